@@ -0,0 +1,153 @@
+package labeler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/jimschubert/labeler/model"
+)
+
+// actionsEscaper escapes the message of a workflow command, see https://github.com/actions/toolkit/blob/main/packages/core/src/command.ts
+var actionsEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+
+// actionsPropertyEscaper escapes a property of a workflow command, such as the file of an annotation
+var actionsPropertyEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+
+// reportToActions communicates the result to GitHub Actions: the added and removed labels are written as JSON to the
+// step outputs ($GITHUB_OUTPUT), the evaluated labels are appended as a Markdown table to the job summary
+// ($GITHUB_STEP_SUMMARY), and problems with the config are annotated with their line and column.
+func (l *Labeler) reportToActions(result *Result, err error) error {
+	var problems model.ConfigErrors
+	if errors.As(err, &problems) {
+		for _, problem := range problems {
+			_, _ = fmt.Fprintln(l.writer(), annotation(l.configPath, problem))
+		}
+	}
+	if result == nil {
+		return nil
+	}
+
+	var errs []error
+	if path := os.Getenv("GITHUB_OUTPUT"); path != "" {
+		errs = append(errs, appendToFile(path, func(w io.Writer) error {
+			return writeActionsOutputs(w, result)
+		}))
+	}
+	if path := os.Getenv("GITHUB_STEP_SUMMARY"); path != "" {
+		errs = append(errs, appendToFile(path, func(w io.Writer) error {
+			_, err := io.WriteString(w, summaryOf(result))
+			return err
+		}))
+	}
+	return errors.Join(errs...)
+}
+
+// annotation formats a problem with the config as an error annotation of the file the problem was found in, which
+// defaults to the config file
+func annotation(file string, problem model.ConfigError) string {
+	if problem.File != "" {
+		file = problem.File
+	}
+	properties := []string{"file=" + actionsPropertyEscaper.Replace(file)}
+	if problem.Line > 0 {
+		properties = append(properties, fmt.Sprintf("line=%d", problem.Line))
+	}
+	if problem.Column > 0 {
+		properties = append(properties, fmt.Sprintf("col=%d", problem.Column))
+	}
+	message := problem.Message
+	if problem.Path != "" {
+		message = problem.Path + ": " + message
+	}
+	return fmt.Sprintf("::error %s::%s", strings.Join(properties, ","), actionsEscaper.Replace(message))
+}
+
+// writeActionsOutputs writes the labels and removed outputs, each a JSON array of label names
+func writeActionsOutputs(w io.Writer, result *Result) error {
+	outputs := []struct {
+		name   string
+		labels []string
+	}{
+		{name: "labels", labels: result.Added},
+		{name: "removed", labels: result.Removed},
+	}
+	for _, output := range outputs {
+		labels := output.labels
+		if labels == nil {
+			labels = []string{}
+		}
+		b, err := json.Marshal(labels)
+		if err != nil {
+			return err
+		}
+		if _, err = fmt.Fprintf(w, "%s=%s\n", output.name, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// summaryOf formats the evaluated and removed labels as a Markdown table, along with the matches of each label
+func summaryOf(result *Result) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("### Labeler: %s #%d\n\n", result.Event, result.Number))
+	if len(result.Evaluated) == 0 && len(result.Removed) == 0 {
+		sb.WriteString("No labels matched.\n\n")
+		return sb.String()
+	}
+
+	sb.WriteString("| Label | Result | Matches |\n")
+	sb.WriteString("|-------|--------|---------|\n")
+	row := func(label, outcome string) {
+		matches := make([]string, 0, len(result.Matches[label]))
+		for _, m := range result.Matches[label] {
+			matches = append(matches, fmt.Sprintf("%s `%s`", m.Field, m.Text))
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s |\n", markdownCell(label), markdownCell(outcome), markdownCell(strings.Join(matches, "<br>"))))
+	}
+	for _, label := range result.Evaluated {
+		switch {
+		case slices.Contains(result.Added, label):
+			row(label, "added")
+		case slices.Contains(result.Removed, label):
+			row(label, "removed")
+		default:
+			for _, skipped := range result.Skipped {
+				if skipped.Name == label {
+					row(label, "skipped: "+skipped.Reason)
+					break
+				}
+			}
+		}
+	}
+	for _, label := range result.Removed {
+		if !slices.Contains(result.Evaluated, label) {
+			row(label, "removed")
+		}
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// markdownCell escapes text for use within a cell of a Markdown table
+func markdownCell(text string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(text)
+}
+
+// appendToFile opens the file for appending, creating it if necessary, and writes to it
+func appendToFile(path string, write func(w io.Writer) error) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("unable to open %q: %w", path, err)
+	}
+	if err = write(f); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("unable to write %q: %w", path, err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,208 @@
+package labeler
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-github/v50/github"
+	"github.com/jimschubert/labeler/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const actionsConfig = `labels:
+  'bug':
+    include: ['\bbug\b']
+  'enhancement':
+    include: ['\bfeature\b']
+`
+
+func TestLabeler_ExecuteWithResult_actions(t *testing.T) {
+	tests := []struct {
+		name    string
+		event   string
+		data    string
+		summary string
+	}{
+		{
+			name:  "issue",
+			event: "issues",
+			data:  `{"issue":{"number":1,"title":"a feature | a bug","labels":[{"name":"bug"}]}}`,
+			summary: "### Labeler: issues #1\n\n" +
+				"| Label | Result | Matches |\n" +
+				"|-------|--------|---------|\n" +
+				"| bug | skipped: already applied | title `bug` |\n" +
+				"| enhancement | added | title `feature` |\n\n",
+		},
+		{
+			name:  "pull request",
+			event: "pull_request",
+			data:  `{"pull_request":{"number":1,"title":"a feature","body":"fixes a bug","labels":[{"name":"bug"}]}}`,
+			summary: "### Labeler: pull_request #1\n\n" +
+				"| Label | Result | Matches |\n" +
+				"|-------|--------|---------|\n" +
+				"| bug | skipped: already applied | body `bug` |\n" +
+				"| enhancement | added | title `feature` |\n\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			output := filepath.Join(dir, "output")
+			summary := filepath.Join(dir, "summary")
+			t.Setenv("GITHUB_OUTPUT", output)
+			t.Setenv("GITHUB_STEP_SUMMARY", summary)
+
+			mockClient := new(mockRichClient)
+			l := &Labeler{
+				Owner:      ptr("owner"),
+				Repo:       ptr("repo"),
+				Event:      ptr(tt.event),
+				ID:         ptr(1),
+				Data:       ptr(tt.data),
+				client:     mockClient,
+				configPath: ".github/labeler.yml",
+				actions:    true,
+			}
+			mockClient.On("DownloadContents", mock.Anything, "owner", "repo", ".github/labeler.yml", mock.Anything).
+				Return(io.NopCloser(bytes.NewReader([]byte(actionsConfig))), nil, nil)
+			mockClient.On("AddLabelsToIssue", mock.Anything, "owner", "repo", 1, []string{"enhancement"}).
+				Return([]*github.Label{{Name: ptr("enhancement")}}, nil, nil)
+
+			_, err := l.ExecuteWithResult(context.Background())
+			assert.NoError(t, err)
+
+			b, err := os.ReadFile(output)
+			assert.NoError(t, err)
+			assert.Equal(t, "labels=[\"enhancement\"]\nremoved=[]\n", string(b))
+
+			b, err = os.ReadFile(summary)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.summary, string(b))
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLabeler_ExecuteWithResult_actions_annotates_config(t *testing.T) {
+	t.Setenv("GITHUB_OUTPUT", "")
+	t.Setenv("GITHUB_STEP_SUMMARY", "")
+
+	var out bytes.Buffer
+	mockClient := new(mockRichClient)
+	l := &Labeler{
+		Owner:      ptr("owner"),
+		Repo:       ptr("repo"),
+		Event:      ptr("issues"),
+		ID:         ptr(1),
+		client:     mockClient,
+		configPath: ".github/labeler.yml",
+		out:        &out,
+		actions:    true,
+	}
+	mockClient.On("DownloadContents", mock.Anything, "owner", "repo", ".github/labeler.yml", mock.Anything).
+		Return(io.NopCloser(bytes.NewReader([]byte(`labels:
+  'bug':
+    include:
+      - '(bug'
+`))), nil, nil)
+
+	result, err := l.ExecuteWithResult(context.Background())
+	assert.Nil(t, result)
+	var problems model.ConfigErrors
+	assert.ErrorAs(t, err, &problems)
+	assert.Equal(t, "::error file=.github/labeler.yml,line=4,col=9::/labels/bug/include/0: "+problems[0].Message+"\n", out.String())
+}
+
+func TestLabeler_ExecuteWithResult_actions_annotates_extended_config(t *testing.T) {
+	t.Setenv("GITHUB_OUTPUT", "")
+	t.Setenv("GITHUB_STEP_SUMMARY", "")
+
+	var out bytes.Buffer
+	mockClient := new(mockRichClient)
+	l := &Labeler{
+		Owner:      ptr("owner"),
+		Repo:       ptr("repo"),
+		Event:      ptr("issues"),
+		ID:         ptr(1),
+		client:     mockClient,
+		configPath: ".github/labeler.yml",
+		out:        &out,
+		actions:    true,
+	}
+	mockClient.On("DownloadContents", mock.Anything, "owner", "repo", ".github/labeler.yml", mock.Anything).
+		Return(io.NopCloser(bytes.NewReader([]byte("extends: my-org/configs/labeler/base.yml@v1\n"))), nil, nil)
+	mockClient.On("DownloadContents", mock.Anything, "my-org", "configs", "labeler/base.yml", mock.Anything).
+		Return(io.NopCloser(bytes.NewReader([]byte(`labels:
+  'bug':
+    include:
+      - '(bug'
+`))), nil, nil)
+
+	_, err := l.ExecuteWithResult(context.Background())
+	var problems model.ConfigErrors
+	assert.ErrorAs(t, err, &problems)
+	assert.Equal(t, "::error file=my-org/configs/labeler/base.yml@v1,line=4,col=9::/labels/bug/include/0: "+problems[0].Message+"\n", out.String())
+}
+
+func TestLabeler_ExecuteWithResult_actions_annotates_config_directory(t *testing.T) {
+	t.Setenv("GITHUB_OUTPUT", "")
+	t.Setenv("GITHUB_STEP_SUMMARY", "")
+
+	root := t.TempDir()
+	dir := filepath.Join(root, ".github", "labeler.d")
+	assert.NoError(t, os.MkdirAll(dir, 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "a.yml"), []byte("labels:\n  'bug':\n    include: ['bug']\n"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "b.yml"), []byte("labels:\n  'docs':\n    include: ['(docs']\n"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "c.yml"), []byte("labels:\n  'docs':\n    include: ['docs']\n  'bug':\n    include: ['crash']\n"), 0o644))
+
+	newLabeler := func(out io.Writer) *Labeler {
+		return &Labeler{
+			Owner:        ptr("owner"),
+			Repo:         ptr("repo"),
+			Event:        ptr("issues"),
+			ID:           ptr(1),
+			configPath:   ".github/labeler.d/",
+			configSource: LocalConfigSource(root),
+			out:          out,
+			actions:      true,
+		}
+	}
+
+	var out bytes.Buffer
+	_, err := newLabeler(&out).ExecuteWithResult(context.Background())
+	var problems model.ConfigErrors
+	assert.ErrorAs(t, err, &problems)
+	assert.Equal(t, "::error file=.github/labeler.d/b.yml,line=3,col=15::/labels/docs/include/0: "+problems[0].Message+"\n", out.String())
+
+	assert.NoError(t, os.Remove(filepath.Join(dir, "b.yml")))
+	out.Reset()
+	_, err = newLabeler(&out).ExecuteWithResult(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, "::error file=.github/labeler.d/c.yml,line=4,col=3::/labels/bug: "+
+		`label "bug" is defined differently by ".github/labeler.d/a.yml" and ".github/labeler.d/c.yml"`+"\n", out.String())
+}
+
+func TestAnnotation(t *testing.T) {
+	assert.Equal(t, "::error file=a%2Cb.yml::line one%0Aline two 100%25",
+		annotation("a,b.yml", model.ConfigError{Message: "line one\nline two 100%"}))
+	assert.Equal(t, "::error file=.github/labeler.yml,line=3,col=5::/labels/bug: invalid",
+		annotation(".github/labeler.yml", model.ConfigError{Path: "/labels/bug", Line: 3, Column: 5, Message: "invalid"}))
+	assert.Equal(t, "::error file=base.yml,line=3,col=5::/labels/bug: invalid",
+		annotation(".github/labeler.yml", model.ConfigError{File: "base.yml", Path: "/labels/bug", Line: 3, Column: 5, Message: "invalid"}))
+}
+
+func TestSummaryOf_removed(t *testing.T) {
+	got := summaryOf(&Result{Event: "pull_request", Number: 2, Removed: []string{"size/XS"}})
+	assert.Equal(t, "### Labeler: pull_request #2\n\n"+
+		"| Label | Result | Matches |\n"+
+		"|-------|--------|---------|\n"+
+		"| size/XS | removed |  |\n\n", got)
+
+	got = summaryOf(&Result{Event: "issues", Number: 3})
+	assert.Equal(t, "### Labeler: issues #3\n\nNo labels matched.\n\n", got)
+}
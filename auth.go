@@ -0,0 +1,155 @@
+package labeler
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/go-github/v50/github"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+)
+
+// appCredentials identify a GitHub App, and the API with which it is registered
+type appCredentials struct {
+	appID      int64
+	privateKey []byte
+	baseURL    string
+	uploadURL  string
+}
+
+// appTokenSource authenticates as a GitHub App installation. It signs a JWT with the app's private key and exchanges
+// it for an installation access token, so comments and labels are attributed to the app.
+type appTokenSource struct {
+	ctx            context.Context
+	appID          int64
+	installationID int64
+	owner          string
+	repo           string
+	key            *rsa.PrivateKey
+	client         *github.Client
+	now            func() time.Time
+}
+
+// appTransport authenticates requests as the GitHub App itself, rather than an installation
+type appTransport struct {
+	appID int64
+	key   *rsa.PrivateKey
+	base  http.RoundTripper
+	now   func() time.Time
+}
+
+// tokenSource constructs a source of installation tokens. When installationID is zero, the installation is looked up
+// from the owner and repo on first use. Tokens are reused until shortly before they expire.
+func (a appCredentials) tokenSource(ctx context.Context, installationID int64, owner, repo string) (oauth2.TokenSource, error) {
+	key, err := parsePrivateKey(a.privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := &appTransport{appID: a.appID, key: key, base: http.DefaultTransport, now: time.Now}
+	client, err := newGitHubClient(&http.Client{Transport: transport}, a.baseURL, a.uploadURL)
+	if err != nil {
+		return nil, err
+	}
+	source := &appTokenSource{
+		ctx:            ctx,
+		appID:          a.appID,
+		installationID: installationID,
+		owner:          owner,
+		repo:           repo,
+		key:            key,
+		client:         client,
+		now:            time.Now,
+	}
+	return oauth2.ReuseTokenSource(nil, source), nil
+}
+
+// Token implements oauth2.TokenSource, creating a new installation access token
+func (s *appTokenSource) Token() (*oauth2.Token, error) {
+	ctx, cancel := context.WithTimeout(s.ctx, 10*time.Second)
+	defer cancel()
+
+	if s.installationID == 0 {
+		installation, _, err := s.client.Apps.FindRepositoryInstallation(ctx, s.owner, s.repo)
+		if err != nil {
+			return nil, fmt.Errorf("unable to find the installation of app %d for %s/%s: %w", s.appID, s.owner, s.repo, err)
+		}
+		s.installationID = installation.GetID()
+	}
+
+	token, _, err := s.client.Apps.CreateInstallationToken(ctx, s.installationID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create a token for installation %d of app %d: %w", s.installationID, s.appID, err)
+	}
+	log.Debugf("Created a token for installation %d of app %d, expiring at %s", s.installationID, s.appID, token.GetExpiresAt())
+
+	return &oauth2.Token{AccessToken: token.GetToken(), TokenType: "token", Expiry: token.GetExpiresAt().Time}, nil
+}
+
+// RoundTrip implements http.RoundTripper, adding a newly signed JWT to each request
+func (t *appTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	jwt, err := signAppJWT(t.appID, t.key, t.now())
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	return t.base.RoundTrip(req)
+}
+
+// signAppJWT creates the RS256 JWT identifying a GitHub App. The issued time is backdated to allow for clock drift,
+// and the expiration is within GitHub's ten minute maximum.
+func signAppJWT(appID int64, key *rsa.PrivateKey, now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]int64{
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(9 * time.Minute).Unix(),
+		"iss": appID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("unable to sign app JWT: %w", err)
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// parsePrivateKey parses a PEM encoded RSA private key, in either PKCS #1 (as downloaded from GitHub) or PKCS #8 form
+func parsePrivateKey(b []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, errors.New("the GitHub App private key must be PEM encoded")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse the GitHub App private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("the GitHub App private key must be an RSA key")
+	}
+	return key, nil
+}
@@ -0,0 +1,156 @@
+package labeler
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v50/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestKey(t *testing.T) *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	return key
+}
+
+// verifyAppJWT checks the signature of a JWT, returning its claims
+func verifyAppJWT(t *testing.T, key *rsa.PrivateKey, jwt string) map[string]int64 {
+	parts := strings.Split(jwt, ".")
+	if !assert.Len(t, parts, 3) {
+		return nil
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	assert.NoError(t, err)
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	assert.NoError(t, rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature))
+
+	b, err := base64.RawURLEncoding.DecodeString(parts[1])
+	assert.NoError(t, err)
+	var claims map[string]int64
+	assert.NoError(t, json.Unmarshal(b, &claims))
+	return claims
+}
+
+func TestSignAppJWT(t *testing.T) {
+	key := newTestKey(t)
+	now := time.Unix(1700000000, 0)
+
+	jwt, err := signAppJWT(123, key, now)
+	assert.NoError(t, err)
+
+	claims := verifyAppJWT(t, key, jwt)
+	assert.Equal(t, map[string]int64{"iat": 1699999940, "exp": 1700000540, "iss": 123}, claims)
+}
+
+func TestParsePrivateKey(t *testing.T) {
+	key := newTestKey(t)
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		pem     []byte
+		wantErr string
+	}{
+		{
+			name: "pkcs1",
+			pem:  pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}),
+		},
+		{
+			name: "pkcs8",
+			pem:  pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}),
+		},
+		{
+			name:    "not pem",
+			pem:     []byte("not a key"),
+			wantErr: "the GitHub App private key must be PEM encoded",
+		},
+		{
+			name:    "invalid key",
+			pem:     pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("invalid")}),
+			wantErr: "unable to parse the GitHub App private key",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePrivateKey(tt.pem)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, key.Equal(got))
+		})
+	}
+}
+
+func TestAppTokenSource_Token(t *testing.T) {
+	key := newTestKey(t)
+	expiry := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		claims := verifyAppJWT(t, key, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+		assert.Equal(t, int64(123), claims["iss"])
+
+		switch r.URL.Path {
+		case "/repos/owner/repo/installation":
+			_ = json.NewEncoder(w).Encode(github.Installation{ID: ptr(int64(42))})
+		case "/app/installations/42/access_tokens":
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(github.InstallationToken{Token: ptr("ghs_token"), ExpiresAt: &github.Timestamp{Time: expiry}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := github.NewClient(&http.Client{Transport: &appTransport{appID: 123, key: key, base: http.DefaultTransport, now: time.Now}})
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	source := &appTokenSource{ctx: context.Background(), appID: 123, owner: "owner", repo: "repo", key: key, client: client, now: time.Now}
+
+	token, err := source.Token()
+	assert.NoError(t, err)
+	assert.Equal(t, "ghs_token", token.AccessToken)
+	assert.True(t, expiry.Equal(token.Expiry))
+
+	_, err = source.Token()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"GET /repos/owner/repo/installation",
+		"POST /app/installations/42/access_tokens",
+		"POST /app/installations/42/access_tokens",
+	}, paths, "the installation is only looked up once")
+}
+
+func TestNewWithOptions_app_requires_private_key(t *testing.T) {
+	_, err := NewWithOptions(WithOwner("owner"), WithRepo("repo"), WithID(1), WithAppID(123))
+	assert.EqualError(t, err, "a private key is required to authenticate as a GitHub App")
+
+	_, err = NewWithOptions(WithOwner("owner"), WithRepo("repo"), WithID(1), WithAppID(123), WithPrivateKey([]byte("invalid")))
+	assert.EqualError(t, err, "the GitHub App private key must be PEM encoded")
+
+	_, err = NewWithOptions(WithOwner("owner"), WithRepo("repo"), WithID(1), WithAppID(123), WithPrivateKeyFile("testdata/missing.pem"))
+	assert.ErrorContains(t, err, "could not read the GitHub App private key")
+
+	key := newTestKey(t)
+	l, err := NewWithOptions(WithOwner("owner"), WithRepo("repo"), WithID(1), WithAppID(123), WithInstallationID(42),
+		WithPrivateKey(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})))
+	assert.NoError(t, err)
+	assert.NotNil(t, l.client)
+}
@@ -0,0 +1,255 @@
+package labeler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v50/github"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultBackfillConcurrency = 4
+
+	// rateLimitReserve is the number of requests left in reserve when pausing for the rate limit to reset; it allows
+	// for the requests of items which are already in flight.
+	rateLimitReserve = 50
+)
+
+// BackfillOptions filter and control the labeling of existing issues and pull requests
+type BackfillOptions struct {
+	// State of the issues and pull requests to label: open (default), closed, or all
+	State string
+	// CreatedSince limits labeling to issues and pull requests created at or after this time, when non-zero
+	CreatedSince time.Time
+	// Unlabeled limits labeling to issues and pull requests which don't have any labels
+	Unlabeled bool
+	// Concurrency is the maximum number of issues and pull requests labeled at once
+	Concurrency int
+	// CheckpointFile records the numbers of issues and pull requests which have been labeled, allowing an interrupted
+	// backfill to resume where it left off
+	CheckpointFile string
+}
+
+// checkpoint records the issue and pull request numbers which have been labeled by a backfill
+type checkpoint struct {
+	mu        sync.Mutex
+	path      string
+	processed map[int]bool
+}
+
+// Backfill applies labels to the existing issues and pull requests of a repository, using the same rules as Execute.
+// Options identifying a single issue or pull request (WithID, WithEvent, WithData) are ignored, as is WithActions.
+func Backfill(opts BackfillOptions, labelOpts ...OptFn) error {
+	l, err := NewWithOptions(backfillOptions(labelOpts)...)
+	if err != nil {
+		return err
+	}
+	l.Data = nil
+	return l.backfill(opts)
+}
+
+// backfillOptions are the options of the caller, followed by those targeting no single issue or pull request. Results
+// of a backfill aren't reported to GitHub Actions, so matches needn't be explained for every item.
+func backfillOptions(labelOpts []OptFn) []OptFn {
+	// the caller's options are copied, rather than appended to in place
+	return append(labelOpts[:len(labelOpts):len(labelOpts)], WithID(0), WithEvent(issue), WithActions(false))
+}
+
+func (l *Labeler) backfill(opts BackfillOptions) error {
+	if err := l.checkPreconditions(); err != nil {
+		return err
+	}
+
+	c, err := l.retrieveConfig()
+	if err != nil {
+		return err
+	}
+	l.config = c
+
+	progress, err := loadCheckpoint(opts.CheckpointFile)
+	if err != nil {
+		return err
+	}
+
+	listOpts := &github.IssueListByRepoOptions{State: opts.State, Sort: "created", Direction: "asc"}
+	if listOpts.State == "" {
+		listOpts.State = "open"
+	}
+	if !opts.CreatedSince.IsZero() {
+		// the API filters by update time, which is never earlier than creation time; creation time is filtered below
+		listOpts.Since = opts.CreatedSince
+	}
+	items, _, err := l.client.ListRepositoryIssues(*l.context, *l.Owner, *l.Repo, listOpts)
+	if err != nil {
+		return fmt.Errorf("unable to list issues: %w", err)
+	}
+
+	pending := make([]*github.Issue, 0, len(items))
+	for _, i := range items {
+		switch {
+		case progress.done(i.GetNumber()):
+		case !opts.CreatedSince.IsZero() && i.GetCreatedAt().Time.Before(opts.CreatedSince):
+		case opts.Unlabeled && len(i.Labels) > 0:
+		default:
+			pending = append(pending, i)
+		}
+	}
+	log.Infof("Backfilling %d of %d issues and pull requests", len(pending), len(items))
+
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultBackfillConcurrency
+	}
+
+	work := make(chan *github.Issue)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var errs []error
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				err := l.backfillItem(i)
+				if err == nil && !l.dryRun {
+					err = progress.record(i.GetNumber())
+				}
+				if err != nil {
+					log.WithFields(log.Fields{"err": err, "number": i.GetNumber()}).Error("Unable to label.")
+					mu.Lock()
+					errs = append(errs, fmt.Errorf("#%d: %w", i.GetNumber(), err))
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+feed:
+	for _, i := range pending {
+		select {
+		case work <- i:
+		case <-(*l.context).Done():
+			break feed
+		}
+	}
+	close(work)
+	wg.Wait()
+
+	if err := (*l.context).Err(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// backfillItem labels a single issue or pull request using a copy of the Labeler targeting that item
+func (l *Labeler) backfillItem(i *github.Issue) error {
+	if err := l.limiter.wait(*l.context, rateLimitReserve); err != nil {
+		return err
+	}
+
+	item := *l
+	number := i.GetNumber()
+	item.ID = &number
+	item.files = nil
+
+	event := issue
+	if i.IsPullRequest() {
+		event = pullRequest
+	}
+	item.Event = &event
+	if !item.eventEnabled() {
+		return nil
+	}
+
+	log.Debugf("Backfilling %s #%d", event, number)
+	if i.IsPullRequest() {
+		// listed pull requests lack the details (e.g. base branch) required by the labeling rules
+		_, err := item.processPullRequest()
+		return err
+	}
+	_, err := item.labelIssue(i)
+	return err
+}
+
+// loadCheckpoint reads a checkpoint file, if one exists. An empty path results in a checkpoint which is never persisted.
+func loadCheckpoint(path string) (*checkpoint, error) {
+	c := &checkpoint{path: path, processed: make(map[int]bool)}
+	if path == "" {
+		return c, nil
+	}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read checkpoint: %w", err)
+	}
+
+	var contents struct {
+		Processed []int `json:"processed"`
+	}
+	if err := json.Unmarshal(b, &contents); err != nil {
+		return nil, fmt.Errorf("unable to parse checkpoint %q: %w", path, err)
+	}
+	for _, number := range contents.Processed {
+		c.processed[number] = true
+	}
+	log.Infof("Resuming from checkpoint %q with %d processed issues and pull requests", path, len(contents.Processed))
+	return c, nil
+}
+
+// done returns true if the number has been recorded as processed
+func (c *checkpoint) done(number int) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.processed[number]
+}
+
+// record marks the number as processed, persisting the checkpoint if it has a path
+func (c *checkpoint) record(number int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.processed[number] = true
+	if c.path == "" {
+		return nil
+	}
+
+	numbers := make([]int, 0, len(c.processed))
+	for n := range c.processed {
+		numbers = append(numbers, n)
+	}
+	sort.Ints(numbers)
+	b, err := json.Marshal(struct {
+		Processed []int `json:"processed"`
+	}{numbers})
+	if err != nil {
+		return err
+	}
+
+	// write to a temporary file first, so an interruption never leaves a truncated checkpoint
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+".*")
+	if err != nil {
+		return fmt.Errorf("unable to write checkpoint: %w", err)
+	}
+	if _, err = tmp.Write(b); err == nil {
+		err = tmp.Close()
+	} else {
+		_ = tmp.Close()
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), c.path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("unable to write checkpoint: %w", err)
+	}
+	return nil
+}
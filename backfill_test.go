@@ -0,0 +1,133 @@
+package labeler
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v50/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const backfillConfig = `labels:
+  'bug':
+    include:
+      - '\bbug[s]?\b'
+  'enhancement':
+    include:
+      - '\bfeat\b'
+    branches:
+      - main
+`
+
+func TestLabeler_backfill(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(mockRichClient)
+	l := newTestLabeler(&ctx, mockClient, backfillConfig, "issues")
+
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	mockClient.On("ListRepositoryIssues", mock.Anything, "owner", "repo", &github.IssueListByRepoOptions{
+		State: "all", Sort: "created", Direction: "asc", Since: since,
+	}).Return([]*github.Issue{
+		{Number: ptr(1), Title: ptr("old bug"), CreatedAt: &github.Timestamp{Time: since.Add(-time.Hour)}},
+		{Number: ptr(2), Title: ptr("a bug"), CreatedAt: &github.Timestamp{Time: since.Add(time.Hour)}},
+		{Number: ptr(3), Title: ptr("labeled bug"), CreatedAt: &github.Timestamp{Time: since.Add(time.Hour)}, Labels: []*github.Label{{Name: ptr("triage")}}},
+		{Number: ptr(4), Title: ptr("feat"), CreatedAt: &github.Timestamp{Time: since.Add(time.Hour)}, PullRequestLinks: &github.PullRequestLinks{}},
+	}, nil, nil)
+	mockClient.On("AddLabelsToIssue", mock.Anything, "owner", "repo", 2, []string{"bug"}).
+		Return([]*github.Label{{Name: ptr("bug")}}, nil, nil)
+	mockClient.On("GetPullRequest", mock.Anything, "owner", "repo", 4).
+		Return(&github.PullRequest{Number: ptr(4), Title: ptr("feat"), Base: &github.PullRequestBranch{Ref: ptr("main")}}, nil, nil)
+	mockClient.On("AddLabelsToIssue", mock.Anything, "owner", "repo", 4, []string{"enhancement"}).
+		Return([]*github.Label{{Name: ptr("enhancement")}}, nil, nil)
+
+	checkpointFile := filepath.Join(t.TempDir(), "checkpoint.json")
+	err := l.backfill(BackfillOptions{
+		State:          "all",
+		CreatedSince:   since,
+		Unlabeled:      true,
+		Concurrency:    2,
+		CheckpointFile: checkpointFile,
+	})
+	assert.NoError(t, err)
+	mockClient.AssertNumberOfCalls(t, "AddLabelsToIssue", 2)
+	mockClient.AssertExpectations(t)
+
+	b, err := os.ReadFile(checkpointFile)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"processed":[2,4]}`, string(b))
+}
+
+func TestBackfillOptions(t *testing.T) {
+	labelOpts := make([]OptFn, 1, 4)
+	labelOpts[0] = WithActions(true)
+
+	for range 2 {
+		options := Opt{}
+		for _, opt := range backfillOptions(labelOpts) {
+			opt(&options)
+		}
+		assert.False(t, options.actions, "results of a backfill aren't reported to GitHub Actions")
+		assert.Equal(t, 0, options.id)
+		assert.Equal(t, issue, options.event)
+	}
+	assert.Nil(t, labelOpts[:cap(labelOpts)][1], "the caller's options aren't appended to in place")
+}
+
+func TestLabeler_backfill_resumes_from_checkpoint(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(mockRichClient)
+	l := newTestLabeler(&ctx, mockClient, backfillConfig, "issues")
+
+	checkpointFile := filepath.Join(t.TempDir(), "checkpoint.json")
+	assert.NoError(t, os.WriteFile(checkpointFile, []byte(`{"processed":[1]}`), 0o600))
+
+	mockClient.On("ListRepositoryIssues", mock.Anything, "owner", "repo", mock.Anything).
+		Return([]*github.Issue{
+			{Number: ptr(1), Title: ptr("a bug")},
+			{Number: ptr(2), Title: ptr("another bug")},
+		}, nil, nil)
+	mockClient.On("AddLabelsToIssue", mock.Anything, "owner", "repo", 2, []string{"bug"}).
+		Return([]*github.Label{{Name: ptr("bug")}}, nil, nil)
+
+	err := l.backfill(BackfillOptions{CheckpointFile: checkpointFile})
+	assert.NoError(t, err)
+	mockClient.AssertNotCalled(t, "AddLabelsToIssue", mock.Anything, "owner", "repo", 1, mock.Anything)
+	mockClient.AssertExpectations(t)
+
+	b, err := os.ReadFile(checkpointFile)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"processed":[1,2]}`, string(b))
+}
+
+func TestLabeler_backfill_waits_for_rate_limit(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(mockRichClient)
+	l := newTestLabeler(&ctx, mockClient, backfillConfig, "issues")
+
+	now := time.Unix(1700000000, 0)
+	var slept time.Duration
+	l.limiter = &rateLimiter{
+		remaining: 10,
+		reset:     now.Add(time.Minute),
+		now:       func() time.Time { return now },
+		sleep: func(_ context.Context, d time.Duration) error {
+			slept += d
+			now = now.Add(d)
+			return nil
+		},
+	}
+
+	mockClient.On("ListRepositoryIssues", mock.Anything, "owner", "repo", mock.Anything).
+		Return([]*github.Issue{{Number: ptr(1), Title: ptr("a bug")}}, nil, nil)
+	mockClient.On("AddLabelsToIssue", mock.Anything, "owner", "repo", 1, []string{"bug"}).
+		Return([]*github.Label{{Name: ptr("bug")}}, nil, nil)
+
+	err := l.backfill(BackfillOptions{Concurrency: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, time.Minute+time.Second, slept)
+	mockClient.AssertExpectations(t)
+}
@@ -0,0 +1,47 @@
+package labeler
+
+import (
+	"fmt"
+
+	"github.com/jimschubert/labeler"
+)
+
+// LabelsCmd manages the labels of a repository
+type LabelsCmd struct {
+	Sync LabelsSyncCmd `cmd:"" help:"Create or update repository labels to match the color and description of each label in the config"`
+}
+
+// LabelsSyncCmd creates or updates repository labels to match the config, printing the changes before applying them
+type LabelsSyncCmd struct {
+	RepoFlags `embed:""`
+	DryRun    bool `name:"dry-run" help:"Print the label changes, without applying them"`
+}
+
+func (c *LabelsSyncCmd) Run() error {
+	l, err := labeler.NewWithOptions(append(c.options(), labeler.WithID(0))...)
+	if err != nil {
+		return fmt.Errorf("could not initialize labeler: %w", err)
+	}
+
+	changes, err := l.PlanLabels()
+	if err != nil {
+		return fmt.Errorf("could not plan label changes: %w", err)
+	}
+	if len(changes) == 0 {
+		fmt.Printf("labels of %s/%s match the config\n", c.Owner, c.Repo)
+		return nil
+	}
+
+	for _, change := range changes {
+		fmt.Println(change)
+	}
+	if c.DryRun {
+		return nil
+	}
+
+	if err = l.ApplyLabelChanges(changes); err != nil {
+		return fmt.Errorf("could not apply label changes: %w", err)
+	}
+	fmt.Printf("applied %d label change(s) to %s/%s\n", len(changes), c.Owner, c.Repo)
+	return nil
+}
@@ -3,6 +3,7 @@ package labeler
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/alecthomas/kong"
 	"github.com/jimschubert/labeler"
@@ -14,35 +15,82 @@ var date = "unk"
 var commit = "unk"
 var projectName = "labeler"
 
+// CLI is the root of the command line interface
 type CLI struct {
-	Owner      string           `short:"o" env:"GITHUB_ACTOR" help:"GitHub Owner/Org name [GITHUB_ACTOR]"`
-	Repo       string           `short:"r" env:"GITHUB_REPO" help:"GitHub Repo name [GITHUB_REPO]"`
-	Type       string           `short:"t" env:"GITHUB_EVENT_NAME" help:"The target event type to label (issues or pull_request) [GITHUB_EVENT_NAME]"`
-	Fields     []string         `default:"title,body" help:"Fields to evaluate for labeling (title, body)"`
-	ID         int              `help:"The integer id of the issue or pull request"`
-	Data       string           `help:"A JSON string of the 'event' type (issue event or pull request event)"`
-	ConfigPath string           `name:"config-path" help:"A custom config path, relative to the repository root"`
-	Version    kong.VersionFlag `short:"v" help:"Print version information"`
+	Label    LabelCmd         `cmd:"" default:"withargs" help:"Apply labels to an issue or pull request (default)"`
+	Explain  ExplainCmd       `cmd:"" help:"Explain which patterns match each label, without modifying the issue or pull request"`
+	Backfill BackfillCmd      `cmd:"" help:"Apply labels to the existing issues and pull requests of a repository"`
+	Serve    ServeCmd         `cmd:"" help:"Receive GitHub webhooks, applying labels as issues and pull requests are opened or edited"`
+	Labels   LabelsCmd        `cmd:"" help:"Manage the labels of a repository"`
+	Validate ValidateCmd      `cmd:"" help:"Validate a local labeler config file"`
+	Version  kong.VersionFlag `short:"v" help:"Print version information"`
 }
 
-func (c *CLI) Run() error {
-	labelOpts := make([]labeler.OptFn, 0)
-	labelOpts = append(labelOpts, labeler.WithOwner(c.Owner))
-	labelOpts = append(labelOpts, labeler.WithRepo(c.Repo))
-	labelOpts = append(labelOpts, labeler.WithEvent(c.Type))
-	if c.ID > 0 {
-		labelOpts = append(labelOpts, labeler.WithID(c.ID))
-	}
-	if c.Data != "" {
-		labelOpts = append(labelOpts, labeler.WithData(c.Data))
-	}
-	if c.ConfigPath != "" {
-		labelOpts = append(labelOpts, labeler.WithConfigPath(c.ConfigPath))
-	}
-	if len(c.Fields) > 0 {
-		fieldFlags := labeler.ParseFieldFlags(c.Fields)
-		labelOpts = append(labelOpts, labeler.WithFields(fieldFlags))
+// AuthFlags configure the GitHub API, and authentication as a GitHub App. Without an app ID, the GITHUB_TOKEN
+// environment variable is used.
+type AuthFlags struct {
+	APIURL         string `name:"api-url" env:"GITHUB_API_URL" help:"The GitHub API URL, e.g. https://github.example.com/api/v3 for GitHub Enterprise Server [GITHUB_API_URL]"`
+	UploadURL      string `name:"upload-url" help:"The GitHub upload URL; derived from --api-url if omitted"`
+	AppID          int64  `name:"app-id" env:"GITHUB_APP_ID" help:"Authenticate as this GitHub App, rather than with GITHUB_TOKEN [GITHUB_APP_ID]"`
+	InstallationID int64  `name:"installation-id" env:"GITHUB_APP_INSTALLATION_ID" help:"The GitHub App installation; looked up if omitted [GITHUB_APP_INSTALLATION_ID]"`
+	PrivateKeyFile string `name:"private-key-file" type:"path" env:"GITHUB_APP_PRIVATE_KEY_FILE" help:"Path to the PEM encoded GitHub App private key [GITHUB_APP_PRIVATE_KEY_FILE]"`
+	PrivateKey     string `name:"private-key" env:"GITHUB_APP_PRIVATE_KEY" help:"The PEM encoded GitHub App private key [GITHUB_APP_PRIVATE_KEY]"`
+}
+
+// RuleFlags determine how issues and pull requests are evaluated
+type RuleFlags struct {
+	Fields     []string `default:"title,body" help:"Fields to evaluate for labeling (title, body, head_branch)"`
+	ConfigPath string   `name:"config-path" xor:"config" help:"A custom config path, relative to the repository root; a path ending with / merges a directory of configs"`
+	ConfigFile string   `name:"config-file" xor:"config,ref" help:"Read the config from a local file, or a directory ending with /, rather than from the repository"`
+	ConfigRef  string   `name:"config-ref" xor:"ref" help:"The branch, tag or commit SHA of the config; defaults to a pull request's base commit, or else the default branch"`
+	SyncLabels bool     `name:"sync-labels" help:"Create or update labels to match their color and description in the config before adding them"`
+}
+
+// RepoFlags identify the repository, and how to evaluate its issues and pull requests
+type RepoFlags struct {
+	Owner     string `short:"o" env:"GITHUB_ACTOR" help:"GitHub Owner/Org name [GITHUB_ACTOR]"`
+	Repo      string `short:"r" env:"GITHUB_REPO" help:"GitHub Repo name [GITHUB_REPO]"`
+	RuleFlags `embed:""`
+	AuthFlags `embed:""`
+}
+
+// TargetFlags identify the issue or pull request to evaluate, and how to evaluate it
+type TargetFlags struct {
+	RepoFlags `embed:""`
+	Type      string `short:"t" env:"GITHUB_EVENT_NAME" help:"The target event type to label (issues, pull_request or issue_comment) [GITHUB_EVENT_NAME]"`
+	ID        int    `help:"The integer id of the issue or pull request"`
+	Data      string `help:"A JSON string of the 'event' type (issue event, pull request event or issue comment event)"`
+}
+
+// LabelCmd applies labels to a single issue or pull request
+type LabelCmd struct {
+	TargetFlags `embed:""`
+	DryRun      bool `name:"dry-run" help:"Print the labels which would be added or removed and why, without modifying the issue or pull request"`
+	Actions     bool `name:"actions" env:"GITHUB_ACTIONS" help:"Write step outputs, a job summary, and annotations of config problems for GitHub Actions [GITHUB_ACTIONS]"`
+}
+
+// ExplainCmd prints which patterns matched or suppressed each label
+type ExplainCmd struct {
+	TargetFlags `embed:""`
+}
+
+// BackfillCmd applies labels to the existing issues and pull requests of a repository
+type BackfillCmd struct {
+	RepoFlags   `embed:""`
+	State       string    `enum:"open,closed,all" default:"open" help:"State of the issues and pull requests to label (open, closed, all)"`
+	Since       time.Time `format:"2006-01-02" help:"Only label issues and pull requests created on or after this date (YYYY-MM-DD)"`
+	Unlabeled   bool      `help:"Only label issues and pull requests which don't have any labels"`
+	Concurrency int       `default:"4" help:"Maximum number of issues and pull requests to label at once"`
+	Checkpoint  string    `type:"path" help:"A file recording progress, allowing an interrupted backfill to resume"`
+	DryRun      bool      `name:"dry-run" help:"Print the labels which would be added or removed and why, without modifying any issue or pull request"`
+}
+
+func (c *LabelCmd) Run() error {
+	labelOpts := c.options()
+	if c.DryRun {
+		labelOpts = append(labelOpts, labeler.WithDryRun(true))
 	}
+	labelOpts = append(labelOpts, labeler.WithActions(c.Actions))
 
 	l, err := labeler.NewWithOptions(labelOpts...)
 	if err != nil {
@@ -55,6 +103,106 @@ func (c *CLI) Run() error {
 	return nil
 }
 
+func (c *ExplainCmd) Run() error {
+	l, err := labeler.NewWithOptions(c.options()...)
+	if err != nil {
+		return fmt.Errorf("could not initialize labeler: %w", err)
+	}
+	explanations, err := l.Explain()
+	if err != nil {
+		return fmt.Errorf("explain failed: %w", err)
+	}
+	for _, explanation := range explanations {
+		fmt.Println(explanation)
+	}
+	return nil
+}
+
+func (c *BackfillCmd) Run() error {
+	labelOpts := c.RepoFlags.options()
+	if c.DryRun {
+		labelOpts = append(labelOpts, labeler.WithDryRun(true))
+	}
+
+	err := labeler.Backfill(labeler.BackfillOptions{
+		State:          c.State,
+		CreatedSince:   c.Since,
+		Unlabeled:      c.Unlabeled,
+		Concurrency:    c.Concurrency,
+		CheckpointFile: c.Checkpoint,
+	}, labelOpts...)
+	if err != nil {
+		return fmt.Errorf("backfill failed: %w", err)
+	}
+	log.Info("backfill complete!")
+	return nil
+}
+
+func (t *TargetFlags) options() []labeler.OptFn {
+	labelOpts := t.RepoFlags.options()
+	labelOpts = append(labelOpts, labeler.WithEvent(t.Type))
+	if t.ID > 0 {
+		labelOpts = append(labelOpts, labeler.WithID(t.ID))
+	}
+	if t.Data != "" {
+		labelOpts = append(labelOpts, labeler.WithData(t.Data))
+	}
+	return labelOpts
+}
+
+func (r *RepoFlags) options() []labeler.OptFn {
+	labelOpts := r.RuleFlags.options()
+	labelOpts = append(labelOpts, r.AuthFlags.options()...)
+	labelOpts = append(labelOpts, labeler.WithOwner(r.Owner))
+	labelOpts = append(labelOpts, labeler.WithRepo(r.Repo))
+	return labelOpts
+}
+
+func (a *AuthFlags) options() []labeler.OptFn {
+	labelOpts := make([]labeler.OptFn, 0)
+	if a.APIURL != "" {
+		labelOpts = append(labelOpts, labeler.WithBaseURL(a.APIURL))
+	}
+	if a.UploadURL != "" {
+		labelOpts = append(labelOpts, labeler.WithUploadURL(a.UploadURL))
+	}
+	if a.AppID == 0 {
+		return labelOpts
+	}
+	labelOpts = append(labelOpts, labeler.WithAppID(a.AppID))
+	if a.InstallationID != 0 {
+		labelOpts = append(labelOpts, labeler.WithInstallationID(a.InstallationID))
+	}
+	if a.PrivateKeyFile != "" {
+		labelOpts = append(labelOpts, labeler.WithPrivateKeyFile(a.PrivateKeyFile))
+	}
+	if a.PrivateKey != "" {
+		labelOpts = append(labelOpts, labeler.WithPrivateKey([]byte(a.PrivateKey)))
+	}
+	return labelOpts
+}
+
+func (r *RuleFlags) options() []labeler.OptFn {
+	labelOpts := make([]labeler.OptFn, 0)
+	if r.SyncLabels {
+		labelOpts = append(labelOpts, labeler.WithSyncLabels(true))
+	}
+	if r.ConfigPath != "" {
+		labelOpts = append(labelOpts, labeler.WithConfigPath(r.ConfigPath))
+	}
+	if r.ConfigRef != "" {
+		labelOpts = append(labelOpts, labeler.WithConfigRef(r.ConfigRef))
+	}
+	if r.ConfigFile != "" {
+		labelOpts = append(labelOpts, labeler.WithConfigSource(labeler.LocalConfigSource("")), labeler.WithConfigPath(r.ConfigFile))
+	}
+	if len(r.Fields) > 0 {
+		fieldFlags := labeler.ParseFieldFlags(r.Fields)
+		labelOpts = append(labelOpts, labeler.WithFields(fieldFlags))
+	}
+	return labelOpts
+}
+
 // Execute parses CLI arguments and runs the command.
 // This is called by main.main(). It only needs to happen once.
 func Execute() {
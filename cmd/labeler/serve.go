@@ -0,0 +1,61 @@
+package labeler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/jimschubert/labeler"
+	log "github.com/sirupsen/logrus"
+)
+
+// ServeCmd runs an HTTP server receiving GitHub webhooks
+type ServeCmd struct {
+	RuleFlags `embed:""`
+	AuthFlags `embed:""`
+	Addr      string `default:":8080" env:"LABELER_ADDR" help:"Address on which to listen for webhooks [LABELER_ADDR]"`
+	Path      string `default:"/" help:"Path at which webhooks are received"`
+	Secret    string `required:"" env:"GITHUB_WEBHOOK_SECRET" help:"The secret used to sign webhook deliveries [GITHUB_WEBHOOK_SECRET]"`
+	DryRun    bool   `name:"dry-run" help:"Print the labels which would be added or removed and why, without modifying any issue or pull request"`
+}
+
+func (c *ServeCmd) Run() error {
+	labelOpts := c.RuleFlags.options()
+	labelOpts = append(labelOpts, c.AuthFlags.options()...)
+	if c.DryRun {
+		labelOpts = append(labelOpts, labeler.WithDryRun(true))
+	}
+
+	server, err := labeler.NewServer(c.Secret, labelOpts...)
+	if err != nil {
+		return fmt.Errorf("could not initialize server: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(c.Path, server)
+	httpServer := &http.Server{
+		Addr:              c.Addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		_ = httpServer.Shutdown(shutdown)
+	}()
+
+	log.Infof("Listening for webhooks on %s%s", c.Addr, c.Path)
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("server failed: %w", err)
+	}
+	log.Info("server stopped")
+	return nil
+}
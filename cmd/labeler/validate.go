@@ -0,0 +1,44 @@
+package labeler
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/jimschubert/labeler/model"
+)
+
+// ValidateCmd validates a local config file against the embedded JSON schemas
+type ValidateCmd struct {
+	File string `arg:"" optional:"" default:".github/labeler.yml" help:"Path to the labeler config file (default: .github/labeler.yml)"`
+}
+
+func (c *ValidateCmd) Run() error {
+	b, err := os.ReadFile(c.File)
+	if err != nil {
+		return fmt.Errorf("could not read config: %w", err)
+	}
+
+	kind, err := model.Validate(b)
+	var problems model.ConfigErrors
+	if errors.As(err, &problems) {
+		for _, problem := range problems {
+			fmt.Fprintf(os.Stderr, "%s:%d:%d: %s\n", c.File, problem.Line, problem.Column, problemMessage(problem))
+		}
+		return fmt.Errorf("%s is invalid: found %d problem(s)", c.File, len(problems))
+	}
+	if err != nil {
+		return fmt.Errorf("could not validate %s: %w", c.File, err)
+	}
+
+	fmt.Printf("%s is a valid %s config\n", c.File, kind)
+	return nil
+}
+
+// problemMessage formats a problem without its line and column, which are printed separately
+func problemMessage(problem model.ConfigError) string {
+	if problem.Path == "" {
+		return problem.Message
+	}
+	return fmt.Sprintf("%s: %s", problem.Path, problem.Message)
+}
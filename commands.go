@@ -0,0 +1,184 @@
+package labeler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v50/github"
+	"github.com/jimschubert/labeler/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// commandLine matches a slash command on its own line of a comment, e.g. /label bug or /unlabel "help wanted"
+var commandLine = regexp.MustCompile(`^/(label|unlabel)(?:\s+(.*))?$`)
+
+// commandArgument matches a single quoted or unquoted label of a slash command
+var commandArgument = regexp.MustCompile(`"([^"]*)"|'([^']*)'|(\S+)`)
+
+// command is a slash command of a comment
+type command struct {
+	// remove is true for /unlabel, false for /label
+	remove bool
+	// args is the text following the command
+	args string
+}
+
+// processComment runs the /label and /unlabel commands of a newly created comment. Commands are only run for
+// commenters with the permission required by the config, and only for labels declared in the config.
+func (l *Labeler) processComment() (*Result, error) {
+	event, err := l.getCommentEvent()
+	if err != nil {
+		return nil, err
+	}
+	result := l.newResult()
+	if event.GetAction() != "created" {
+		log.Debugf("Ignoring %s comment", event.GetAction())
+		return result, nil
+	}
+
+	comment := event.GetComment()
+	if strings.HasPrefix(comment.GetBody(), commentMarker) || strings.EqualFold(comment.GetUser().GetType(), "bot") {
+		log.Debug("Ignoring comment by a bot")
+		return result, nil
+	}
+
+	commands := parseCommands(comment.GetBody())
+	if len(commands) == 0 {
+		log.Debug("Found 0 commands to run")
+		return result, nil
+	}
+
+	declared := l.config.Rules().Names()
+	var add, remove []string
+	for _, c := range commands {
+		labels, unknown := resolveCommandLabels(c.args, declared)
+		for _, name := range unknown {
+			log.Warnf("Ignoring label %q, which isn't declared in %q", name, l.configPath)
+			result.Evaluated = append(result.Evaluated, name)
+			result.skip(name, fmt.Sprintf("not declared in %q", l.configPath))
+		}
+		if c.remove {
+			remove = append(remove, labels...)
+		} else {
+			add = append(add, labels...)
+		}
+	}
+	result.Evaluated = append(result.Evaluated, add...)
+	result.Evaluated = append(result.Evaluated, remove...)
+
+	login := comment.GetUser().GetLogin()
+	required := "write"
+	if fc, ok := l.config.(*model.FullConfig); ok && fc != nil {
+		required = fc.CommandPermission()
+	}
+	allowed, err := l.hasPermission(login, required)
+	if err != nil {
+		return result, err
+	}
+	if !allowed {
+		log.Infof("Ignoring commands of %s, who doesn't have %s permission", login, required)
+		for _, name := range append(add, remove...) {
+			result.skip(name, fmt.Sprintf("%s doesn't have %s permission", login, required))
+		}
+		return result, nil
+	}
+
+	existing := event.GetIssue().Labels
+	result.Added, err = l.addLabels(add, existing)
+	removed, removeErr := l.removeLabels(remove, existing)
+	result.Removed = removed
+	for _, name := range add {
+		if labelExists(existing, &name) {
+			result.skip(name, "already applied")
+		}
+	}
+	for _, name := range remove {
+		if !labelExists(existing, &name) {
+			result.skip(name, "not applied")
+		}
+	}
+	return result, errors.Join(err, removeErr)
+}
+
+// getCommentEvent parses the issue_comment event payload, which is required as it identifies the comment
+func (l *Labeler) getCommentEvent() (*github.IssueCommentEvent, error) {
+	if l.Data == nil {
+		return nil, fmt.Errorf("the %s event requires the event payload", issueComment)
+	}
+	var event github.IssueCommentEvent
+	if err := json.Unmarshal([]byte(*l.Data), &event); err != nil || event.Comment == nil {
+		return nil, errors.New("failed to unmarshal issue comment data")
+	}
+	return &event, nil
+}
+
+// hasPermission determines whether the user has at least the required permission on the repository
+func (l *Labeler) hasPermission(user, required string) (bool, error) {
+	ctx, cancel := context.WithTimeout(*l.context, 10*time.Second)
+	defer cancel()
+	level, _, err := l.client.GetPermissionLevel(ctx, *l.Owner, *l.Repo, user)
+	if err != nil {
+		return false, fmt.Errorf("unable to get the permission of %s: %w", user, err)
+	}
+	return model.PermissionRank(level.GetPermission()) >= model.PermissionRank(required), nil
+}
+
+// parseCommands finds the slash commands of a comment, each of which must begin a line
+func parseCommands(body string) []command {
+	var commands []command
+	for _, line := range strings.Split(body, "\n") {
+		m := commandLine.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+		commands = append(commands, command{remove: m[1] == "unlabel", args: strings.TrimSpace(m[2])})
+	}
+	return commands
+}
+
+// resolveCommandLabels maps the arguments of a command to declared label names, compared case-insensitively.
+// Arguments are separated by commas or whitespace, and may be quoted; if the whole argument text names a declared
+// label (e.g. /label help wanted), it's used as-is. Arguments which don't name a declared label are returned as unknown.
+func resolveCommandLabels(args string, declared []string) (labels, unknown []string) {
+	if name := findFold(declared, strings.Trim(args, `"'`)); name != "" {
+		return []string{name}, nil
+	}
+
+	var values []string
+	if strings.Contains(args, ",") {
+		for _, value := range strings.Split(args, ",") {
+			values = append(values, strings.Trim(strings.TrimSpace(value), `"'`))
+		}
+	} else {
+		for _, m := range commandArgument.FindAllStringSubmatch(args, -1) {
+			values = append(values, m[1]+m[2]+m[3])
+		}
+	}
+
+	for _, value := range values {
+		if value == "" {
+			continue
+		}
+		if name := findFold(declared, value); name != "" {
+			labels = append(labels, name)
+		} else {
+			unknown = append(unknown, value)
+		}
+	}
+	return labels, unknown
+}
+
+// findFold returns the value equal to name, ignoring case, or an empty string if there is none
+func findFold(values []string, name string) string {
+	for _, value := range values {
+		if strings.EqualFold(value, name) {
+			return value
+		}
+	}
+	return ""
+}
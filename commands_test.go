@@ -0,0 +1,186 @@
+package labeler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/google/go-github/v50/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const commandsConfig = `commands:
+  permission: write
+labels:
+  'bug':
+    include: ['\bbug[s]?\b']
+  'help wanted':
+    include: ['\bhelp\b']
+  'question':
+    include: ['\bquestion\b']
+`
+
+func TestResolveCommandLabels(t *testing.T) {
+	declared := []string{"bug", "help wanted", "question"}
+	tests := []struct {
+		name        string
+		args        string
+		wantLabels  []string
+		wantUnknown []string
+	}{
+		{name: "single", args: "Bug", wantLabels: []string{"bug"}},
+		{name: "label with spaces", args: "help wanted", wantLabels: []string{"help wanted"}},
+		{name: "quoted", args: `"help wanted" question`, wantLabels: []string{"help wanted", "question"}},
+		{name: "comma separated", args: "bug, help wanted", wantLabels: []string{"bug", "help wanted"}},
+		{name: "unknown", args: "bug wontfix", wantLabels: []string{"bug"}, wantUnknown: []string{"wontfix"}},
+		{name: "empty", args: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			labels, unknown := resolveCommandLabels(tt.args, declared)
+			assert.Equal(t, tt.wantLabels, labels)
+			assert.Equal(t, tt.wantUnknown, unknown)
+		})
+	}
+}
+
+func TestParseCommands(t *testing.T) {
+	got := parseCommands("Thanks!\r\n/label bug\n  /unlabel question\nplease /label help\n/labels bug\n/unlabel")
+	assert.Equal(t, []command{
+		{args: "bug"},
+		{remove: true, args: "question"},
+		{remove: true},
+	}, got)
+}
+
+func TestLabeler_Execute_issue_comment(t *testing.T) {
+	payload := func(action, login, body string) *string {
+		text, _ := json.Marshal(body)
+		return ptr(`{"action":"` + action + `","issue":{"number":1,"labels":[{"name":"question"}]},` +
+			`"comment":{"body":` + string(text) + `,"user":{"login":"` + login + `","type":"User"}}}`)
+	}
+	tests := []struct {
+		name       string
+		data       *string
+		permission string
+		wantAdd    []string
+		wantRemove []string
+	}{
+		{
+			name:       "labels and unlabels",
+			data:       payload("created", "maintainer", "/label bug \"help wanted\" wontfix\n/unlabel question\n/unlabel bug"),
+			permission: "admin",
+			wantAdd:    []string{"bug", "help wanted"},
+			wantRemove: []string{"question"},
+		},
+		{
+			name:       "insufficient permission",
+			data:       payload("created", "visitor", "/label bug"),
+			permission: "read",
+		},
+		{
+			name: "edited comments are ignored",
+			data: payload("edited", "maintainer", "/label bug"),
+		},
+		{
+			name: "comments without commands are ignored",
+			data: payload("created", "maintainer", "looks like a bug"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			mockClient := new(mockRichClient)
+			l := &Labeler{
+				Owner:      ptr("owner"),
+				Repo:       ptr("repo"),
+				Event:      ptr("issue_comment"),
+				ID:         ptr(1),
+				Data:       tt.data,
+				context:    &ctx,
+				client:     mockClient,
+				configPath: ".github/labeler.yml",
+			}
+			mockClient.On("DownloadContents", mock.Anything, "owner", "repo", ".github/labeler.yml", mock.Anything).
+				Return(io.NopCloser(bytes.NewReader([]byte(commandsConfig))), nil, nil)
+			if tt.permission != "" {
+				mockClient.On("GetPermissionLevel", mock.Anything, "owner", "repo", mock.Anything).
+					Return(&github.RepositoryPermissionLevel{Permission: ptr(tt.permission)}, nil, nil)
+			}
+			if tt.wantAdd != nil {
+				mockClient.On("AddLabelsToIssue", mock.Anything, "owner", "repo", 1, tt.wantAdd).Return([]*github.Label{}, nil, nil)
+			}
+			for _, name := range tt.wantRemove {
+				mockClient.On("RemoveLabelForIssue", mock.Anything, "owner", "repo", 1, name).Return(nil, nil)
+			}
+
+			result, err := l.ExecuteWithResult(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantAdd, result.Added)
+			assert.Equal(t, tt.wantRemove, result.Removed)
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLabeler_Execute_issue_comment_enable(t *testing.T) {
+	issuePayload := `{"action":"created","issue":{"number":1},"comment":{"body":"/label bug","user":{"login":"maintainer","type":"User"}}}`
+	prPayload := `{"action":"created","issue":{"number":1,"pull_request":{"url":"https://api.github.com/repos/owner/repo/pulls/1"}},` +
+		`"comment":{"body":"/label bug","user":{"login":"maintainer","type":"User"}}}`
+	tests := []struct {
+		name    string
+		enable  string
+		data    string
+		wantAdd bool
+	}{
+		{name: "issue comment with issues disabled", enable: "issues: false", data: issuePayload},
+		{name: "issue comment with prs disabled", enable: "prs: false", data: issuePayload, wantAdd: true},
+		{name: "pull request comment with prs disabled", enable: "prs: false", data: prPayload},
+		{name: "pull request comment with issues disabled", enable: "issues: false", data: prPayload, wantAdd: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			mockClient := new(mockRichClient)
+			l := &Labeler{
+				Owner:      ptr("owner"),
+				Repo:       ptr("repo"),
+				Event:      ptr("issue_comment"),
+				ID:         ptr(1),
+				Data:       ptr(tt.data),
+				context:    &ctx,
+				client:     mockClient,
+				configPath: ".github/labeler.yml",
+			}
+			mockClient.On("DownloadContents", mock.Anything, "owner", "repo", ".github/labeler.yml", mock.Anything).
+				Return(io.NopCloser(bytes.NewReader([]byte("enable:\n  "+tt.enable+"\n"+commandsConfig))), nil, nil)
+			if tt.wantAdd {
+				mockClient.On("GetPermissionLevel", mock.Anything, "owner", "repo", "maintainer").
+					Return(&github.RepositoryPermissionLevel{Permission: ptr("admin")}, nil, nil)
+				mockClient.On("AddLabelsToIssue", mock.Anything, "owner", "repo", 1, []string{"bug"}).Return([]*github.Label{}, nil, nil)
+			}
+
+			result, err := l.ExecuteWithResult(ctx)
+			assert.NoError(t, err)
+			if tt.wantAdd {
+				assert.Equal(t, []string{"bug"}, result.Added)
+			} else {
+				assert.Empty(t, result.Added)
+			}
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLabeler_Execute_issue_comment_requires_payload(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(mockRichClient)
+	l := &Labeler{Owner: ptr("owner"), Repo: ptr("repo"), Event: ptr("issue_comment"), ID: ptr(1), context: &ctx, client: mockClient, configPath: ".github/labeler.yml"}
+	mockClient.On("DownloadContents", mock.Anything, "owner", "repo", ".github/labeler.yml", mock.Anything).
+		Return(io.NopCloser(bytes.NewReader([]byte(commandsConfig))), nil, nil)
+
+	assert.EqualError(t, l.Execute(), "the issue_comment event requires the event payload")
+}
@@ -0,0 +1,233 @@
+package labeler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/google/go-github/v50/github"
+	"github.com/jimschubert/labeler/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// orgConfigRepo is the repository holding an owner's default community health files, and its fallback config
+const orgConfigRepo = ".github"
+
+type (
+	// ConfigSource reads labeler configs, either from a repository through the GitHub API (the default) or from the
+	// local filesystem (see LocalConfigSource)
+	ConfigSource interface {
+		// ReadConfig returns the contents of the config at path
+		ReadConfig(ctx context.Context, path string) ([]byte, error)
+	}
+
+	// ConfigLister is implemented by a ConfigSource which can read a config directory, such as .github/labeler.d/
+	ConfigLister interface {
+		// ListConfigs returns the names of the files of the directory at path
+		ListConfigs(ctx context.Context, path string) ([]string, error)
+	}
+
+	// repositoryConfigSource downloads configs from a repository, at the ref (a branch, tag or commit SHA) or else the
+	// default branch
+	repositoryConfigSource struct {
+		client model.Client
+		owner  string
+		repo   string
+		ref    string
+	}
+
+	// localConfigSource reads configs from the local filesystem
+	localConfigSource struct {
+		root string
+	}
+)
+
+// LocalConfigSource reads configs from the local filesystem, relative to root (e.g. the GitHub Actions workspace). An
+// empty root reads configs relative to the working directory, or from an absolute path.
+func LocalConfigSource(root string) ConfigSource {
+	return localConfigSource{root: root}
+}
+
+// ReadConfig downloads the config at path, relative to the repository root. A config which doesn't exist results in an
+// error wrapping fs.ErrNotExist.
+func (s repositoryConfigSource) ReadConfig(ctx context.Context, path string) ([]byte, error) {
+	r, _, err := s.client.DownloadContents(ctx, s.owner, s.repo, path, &github.RepositoryContentGetOptions{Ref: s.ref})
+	if err != nil {
+		if notFound(err) {
+			return nil, fmt.Errorf("%w: %w", fs.ErrNotExist, err)
+		}
+		return nil, err
+	}
+	defer r.Close()
+
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %q: %w", path, err)
+	}
+	return b, nil
+}
+
+// ListConfigs lists the files of the directory at path, relative to the repository root. A directory which doesn't
+// exist results in an error wrapping fs.ErrNotExist.
+func (s repositoryConfigSource) ListConfigs(ctx context.Context, path string) ([]string, error) {
+	_, entries, _, err := s.client.GetContents(ctx, s.owner, s.repo, strings.TrimSuffix(path, "/"), &github.RepositoryContentGetOptions{Ref: s.ref})
+	if err != nil {
+		if notFound(err) {
+			return nil, fmt.Errorf("%w: %w", fs.ErrNotExist, err)
+		}
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.GetType() == "file" {
+			names = append(names, entry.GetName())
+		}
+	}
+	return names, nil
+}
+
+// ReadConfig reads the config at path, relative to the root of the source
+func (s localConfigSource) ReadConfig(_ context.Context, path string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(s.root, filepath.FromSlash(path)))
+}
+
+// ListConfigs lists the files of the directory at path, relative to the root of the source
+func (s localConfigSource) ListConfigs(_ context.Context, path string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(s.root, filepath.FromSlash(path)))
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.Type().IsRegular() {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
+
+// readConfig reads the config from its source. When the repository has no config at all, the config of the same path
+// in the owner's .github repository is read instead, and returned as the config inherited from.
+func (l *Labeler) readConfig(ctx context.Context, source ConfigSource) ([]byte, []model.ConfigReference, error) {
+	b, err := readConfigAt(ctx, source, l.configPath)
+	if err == nil || l.configSource != nil || strings.EqualFold(*l.Repo, orgConfigRepo) || !errors.Is(err, fs.ErrNotExist) {
+		return b, nil, err
+	}
+
+	log.Infof("%s/%s has no config %q, falling back to %s/%s", *l.Owner, *l.Repo, l.configPath, *l.Owner, orgConfigRepo)
+	fallback := repositoryConfigSource{client: l.client, owner: *l.Owner, repo: orgConfigRepo}
+	b, fallbackErr := readConfigAt(ctx, fallback, l.configPath)
+	if errors.Is(fallbackErr, fs.ErrNotExist) {
+		return nil, nil, err
+	}
+	return b, []model.ConfigReference{{Owner: *l.Owner, Repo: orgConfigRepo, Path: l.configPath}}, fallbackErr
+}
+
+// extend merges config bytes onto the config referenced by their extends key, if any, which may itself extend another.
+// The chain lists the configs being extended, so that a config which (indirectly) extends itself is reported. Every
+// config which was extended is returned, so that the merged config can be discarded once any of them changes.
+func (l *Labeler) extend(ctx context.Context, b []byte, chain []string) ([]byte, []model.ConfigReference, error) {
+	value := model.ExtendsOf(b)
+	if value == "" {
+		return b, nil, nil
+	}
+	reference, err := model.ParseConfigReference(value)
+	if err != nil {
+		return nil, nil, err
+	}
+	if slices.Contains(chain, reference.String()) {
+		return nil, nil, fmt.Errorf("config extends itself: %s", strings.Join(append(chain, reference.String()), " -> "))
+	}
+	chain = append(chain, reference.String())
+
+	log.Infof("Extending config %s", reference)
+	source := repositoryConfigSource{client: l.client, owner: reference.Owner, repo: reference.Repo, ref: reference.Ref}
+	base, err := source.ReadConfig(ctx, reference.Path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to read extended config %s: %w", reference, err)
+	}
+	if _, err = parseConfig(base, reference.String()); err != nil {
+		return nil, nil, err
+	}
+	base, extended, err := l.extend(ctx, base, chain)
+	if err != nil {
+		return nil, nil, err
+	}
+	merged, err := model.MergeConfigs(base, b)
+	return merged, append([]model.ConfigReference{reference}, extended...), err
+}
+
+// readConfigAt reads the config at path from the source. A path ending with / is a config directory, whose *.yml and
+// *.yaml files are each validated, then merged in lexical order (see model.MergeConfigFiles).
+func readConfigAt(ctx context.Context, source ConfigSource, path string) ([]byte, error) {
+	if !isConfigDirectory(path) {
+		return source.ReadConfig(ctx, path)
+	}
+	lister, ok := source.(ConfigLister)
+	if !ok {
+		return nil, fmt.Errorf("unable to read the config directory %q, as its source can't list files", path)
+	}
+	names, err := lister.ListConfigs(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []model.ConfigFile
+	slices.Sort(names)
+	for _, name := range names {
+		if ext := filepath.Ext(name); ext != ".yml" && ext != ".yaml" {
+			continue
+		}
+		file := strings.TrimSuffix(path, "/") + "/" + name
+		b, err := source.ReadConfig(ctx, file)
+		if err != nil {
+			return nil, err
+		}
+		// problems are reported at the lines of each file, rather than those of the merged config, which alone must
+		// define labels
+		if _, err = parseConfigWith(model.ParseConfigFile, b, file); err != nil {
+			return nil, err
+		}
+		files = append(files, model.ConfigFile{Name: file, Content: b})
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%w: no *.yml configs in %q", fs.ErrNotExist, path)
+	}
+	log.Debugf("Merging %d configs of %q", len(files), path)
+	b, err := model.MergeConfigFiles(files...)
+	if err != nil {
+		return nil, fmt.Errorf("could not merge the configs of %q:\n%w", path, err)
+	}
+	return b, nil
+}
+
+// isConfigDirectory determines whether the config path refers to a directory of configs, e.g. .github/labeler.d/
+func isConfigDirectory(path string) bool {
+	return strings.HasSuffix(path, "/")
+}
+
+// isConfigFile determines whether a file of a repository is the config at path, or one of the configs of the config
+// directory at path
+func isConfigFile(path, file string) bool {
+	if isConfigDirectory(path) {
+		return strings.HasPrefix(file, path)
+	}
+	return file == path
+}
+
+// notFound determines whether a download failed because the file, or its directory, doesn't exist
+func notFound(err error) bool {
+	var response *github.ErrorResponse
+	if errors.As(err, &response) {
+		return response.Response != nil && response.Response.StatusCode == http.StatusNotFound
+	}
+	// DownloadContents lists the directory of the file, reporting a missing file with a plain error
+	return strings.HasPrefix(err.Error(), "no file named ")
+}
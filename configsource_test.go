@@ -0,0 +1,330 @@
+package labeler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-github/v50/github"
+	"github.com/jimschubert/labeler/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestLocalConfigSource_ReadConfig(t *testing.T) {
+	root := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(root, ".github"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(root, ".github", "labeler.yml"), []byte("labels: {}\n"), 0o644))
+
+	b, err := LocalConfigSource(root).ReadConfig(context.Background(), ".github/labeler.yml")
+	assert.NoError(t, err)
+	assert.Equal(t, "labels: {}\n", string(b))
+
+	b, err = LocalConfigSource("").ReadConfig(context.Background(), filepath.Join(root, ".github", "labeler.yml"))
+	assert.NoError(t, err)
+	assert.Equal(t, "labels: {}\n", string(b))
+
+	_, err = LocalConfigSource(root).ReadConfig(context.Background(), "missing.yml")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestLabeler_Execute_local_config(t *testing.T) {
+	root := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "labeler.yml"), []byte(`labels:
+  'bug':
+    include:
+      - '\bbug\b'
+`), 0o644))
+
+	ctx := context.Background()
+	mockClient := new(mockRichClient)
+	l := &Labeler{
+		Owner:        ptr("owner"),
+		Repo:         ptr("repo"),
+		Event:        ptr("issues"),
+		ID:           ptr(1),
+		Data:         ptr(`{"issue":{"number":1,"title":"a bug"}}`),
+		context:      &ctx,
+		client:       mockClient,
+		configPath:   "labeler.yml",
+		configSource: LocalConfigSource(root),
+	}
+	mockClient.On("AddLabelsToIssue", mock.Anything, "owner", "repo", 1, []string{"bug"}).
+		Return([]*github.Label{{Name: ptr("bug")}}, nil, nil)
+
+	assert.NoError(t, l.Execute())
+	mockClient.AssertNotCalled(t, "DownloadContents", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockClient.AssertExpectations(t)
+}
+
+func TestLabeler_configRefOf(t *testing.T) {
+	prData := `{"pull_request":{"number":1,"base":{"ref":"main","sha":"abc123"}}}`
+	tests := []struct {
+		name    string
+		l       *Labeler
+		wantRef string
+	}{
+		{name: "configured ref", l: &Labeler{Event: ptr("pull_request"), Data: ptr(prData), configRef: "release/1.x"}, wantRef: "release/1.x"},
+		{name: "pull request base commit", l: &Labeler{Event: ptr("pull_request"), Data: ptr(prData)}, wantRef: "abc123"},
+		{name: "pull request target base commit", l: &Labeler{Event: ptr("pull_request_target"), Data: ptr(prData)}, wantRef: "abc123"},
+		{name: "issues use the default branch", l: &Labeler{Event: ptr("issues"), Data: ptr(`{"issue":{"number":1}}`)}},
+		{name: "pull requests without a payload use the default branch", l: &Labeler{Event: ptr("pull_request")}},
+		{name: "local configs have no ref", l: &Labeler{Event: ptr("pull_request"), Data: ptr(prData), configSource: LocalConfigSource("")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantRef, tt.l.configRefOf())
+		})
+	}
+}
+
+func TestLabeler_Execute_config_ref(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(mockRichClient)
+	configs := newConfigCache()
+	cached := &model.SimpleConfig{}
+	configs.put(configKey("owner", "repo"), cached)
+
+	l := &Labeler{
+		Owner:      ptr("owner"),
+		Repo:       ptr("repo"),
+		Event:      ptr("pull_request_target"),
+		ID:         ptr(1),
+		Data:       ptr(`{"pull_request":{"number":1,"title":"a bug","base":{"ref":"main","sha":"abc123"}}}`),
+		context:    &ctx,
+		client:     mockClient,
+		configPath: ".github/labeler.yml",
+		configs:    configs,
+	}
+	mockClient.On("DownloadContents", mock.Anything, "owner", "repo", ".github/labeler.yml", &github.RepositoryContentGetOptions{Ref: "abc123"}).
+		Return(io.NopCloser(bytes.NewReader([]byte(`labels:
+  'bug':
+    include:
+      - '\bbug\b'
+`))), nil, nil)
+	mockClient.On("AddLabelsToIssue", mock.Anything, "owner", "repo", 1, []string{"bug"}).
+		Return([]*github.Label{{Name: ptr("bug")}}, nil, nil)
+
+	assert.NoError(t, l.Execute())
+	assert.Same(t, cached, configs.get(configKey("owner", "repo")), "the config of the default branch is unchanged")
+	assert.NotNil(t, configs.get(configKeyAt("owner", "repo", "abc123")), "configs read at a ref are cached by ref")
+
+	assert.NoError(t, l.Execute())
+	mockClient.AssertNumberOfCalls(t, "DownloadContents", 1)
+	mockClient.AssertExpectations(t)
+}
+
+func TestLabeler_Execute_extends(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(mockRichClient)
+	l := &Labeler{
+		Owner:      ptr("owner"),
+		Repo:       ptr("repo"),
+		Event:      ptr("issues"),
+		ID:         ptr(1),
+		Data:       ptr(`{"issue":{"number":1,"title":"a crash, won't fix"}}`),
+		context:    &ctx,
+		client:     mockClient,
+		configPath: ".github/labeler.yml",
+	}
+	mockClient.On("DownloadContents", mock.Anything, "owner", "repo", ".github/labeler.yml", mock.Anything).
+		Return(io.NopCloser(bytes.NewReader([]byte(`extends: my-org/configs/labeler/base.yml@v1
+labels:
+  'bug':
+    include: ['\bcrash\b']
+  'wontfix': ~
+`))), nil, nil)
+	mockClient.On("DownloadContents", mock.Anything, "my-org", "configs", "labeler/base.yml", &github.RepositoryContentGetOptions{Ref: "v1"}).
+		Return(io.NopCloser(bytes.NewReader([]byte(`extends: my-org/.github
+labels:
+  'bug':
+    include: ['\bbug\b']
+    color: d73a4a
+`))), nil, nil)
+	mockClient.On("DownloadContents", mock.Anything, "my-org", ".github", ".github/labeler.yml", &github.RepositoryContentGetOptions{}).
+		Return(io.NopCloser(bytes.NewReader([]byte(`labels:
+  'wontfix':
+    include: ['\bwon''t fix\b']
+  'crash':
+    include: ['\bcrash\b']
+`))), nil, nil)
+	mockClient.On("AddLabelsToIssue", mock.Anything, "owner", "repo", 1, []string{"bug", "crash"}).
+		Return([]*github.Label{{Name: ptr("bug")}, {Name: ptr("crash")}}, nil, nil)
+
+	assert.NoError(t, l.Execute())
+	assert.Equal(t, "d73a4a", l.config.Rules().Rule("bug").Label.Color, "inherited values are deep merged")
+	mockClient.AssertExpectations(t)
+}
+
+func TestLabeler_retrieveConfig_extends_cycle(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(mockRichClient)
+	l := &Labeler{
+		Owner:      ptr("owner"),
+		Repo:       ptr("repo"),
+		Event:      ptr("issues"),
+		ID:         ptr(1),
+		context:    &ctx,
+		client:     mockClient,
+		configPath: ".github/labeler.yml",
+	}
+	mockClient.On("DownloadContents", mock.Anything, "owner", "repo", ".github/labeler.yml", mock.Anything).
+		Return(io.NopCloser(bytes.NewReader([]byte("extends: my-org/a\n"))), nil, nil)
+	mockClient.On("DownloadContents", mock.Anything, "my-org", "a", ".github/labeler.yml", mock.Anything).
+		Return(io.NopCloser(bytes.NewReader([]byte("extends: my-org/b\n"))), nil, nil)
+	mockClient.On("DownloadContents", mock.Anything, "my-org", "b", ".github/labeler.yml", mock.Anything).
+		Return(io.NopCloser(bytes.NewReader([]byte("extends: my-org/a\n"))), nil, nil)
+
+	_, err := l.retrieveConfig()
+	assert.EqualError(t, err, "config extends itself: owner/repo/.github/labeler.yml -> my-org/a/.github/labeler.yml -> "+
+		"my-org/b/.github/labeler.yml -> my-org/a/.github/labeler.yml")
+}
+
+func TestLabeler_retrieveConfig_org_fallback(t *testing.T) {
+	missing := errors.New("no file named labeler.yml found in .github")
+	tests := []struct {
+		name    string
+		org     func(*mock.Call)
+		wantErr string
+	}{
+		{
+			name: "uses the config of the org's .github repository",
+			org: func(c *mock.Call) {
+				c.Return(io.NopCloser(bytes.NewReader([]byte("labels:\n  'bug':\n    include: ['bug']\n"))), nil, nil)
+			},
+		},
+		{
+			name: "reports the repository's missing config when the org has none",
+			org: func(c *mock.Call) {
+				c.Return(io.NopCloser(nil), nil, &github.ErrorResponse{Response: &http.Response{StatusCode: http.StatusNotFound}, Message: "Not Found"})
+			},
+			wantErr: "file does not exist: no file named labeler.yml found in .github",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			mockClient := new(mockRichClient)
+			l := &Labeler{
+				Owner:      ptr("owner"),
+				Repo:       ptr("repo"),
+				Event:      ptr("issues"),
+				ID:         ptr(1),
+				context:    &ctx,
+				client:     mockClient,
+				configPath: ".github/labeler.yml",
+			}
+			mockClient.On("DownloadContents", mock.Anything, "owner", "repo", ".github/labeler.yml", mock.Anything).
+				Return(io.NopCloser(nil), nil, missing)
+			tt.org(mockClient.On("DownloadContents", mock.Anything, "owner", ".github", ".github/labeler.yml", mock.Anything))
+
+			c, err := l.retrieveConfig()
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				assert.ErrorIs(t, err, fs.ErrNotExist)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, []string{"bug"}, c.Rules().Names())
+			}
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLabeler_Execute_config_directory(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(mockRichClient)
+	l := &Labeler{
+		Owner:      ptr("owner"),
+		Repo:       ptr("repo"),
+		Event:      ptr("issues"),
+		ID:         ptr(1),
+		Data:       ptr(`{"issue":{"number":1,"title":"a crash in the docs"}}`),
+		context:    &ctx,
+		client:     mockClient,
+		configPath: ".github/labeler.d/",
+	}
+	mockClient.On("GetContents", mock.Anything, "owner", "repo", ".github/labeler.d", &github.RepositoryContentGetOptions{}).
+		Return([]*github.RepositoryContent{
+			{Name: ptr("frontend.yml"), Type: ptr("file")},
+			{Name: ptr("backend.yml"), Type: ptr("file")},
+			{Name: ptr("README.md"), Type: ptr("file")},
+			{Name: ptr("archive"), Type: ptr("dir")},
+		}, nil)
+	mockClient.On("DownloadContents", mock.Anything, "owner", "repo", ".github/labeler.d/backend.yml", mock.Anything).
+		Return(io.NopCloser(bytes.NewReader([]byte("labels:\n  'bug':\n    include: ['\\bcrash\\b']\n"))), nil, nil)
+	mockClient.On("DownloadContents", mock.Anything, "owner", "repo", ".github/labeler.d/frontend.yml", mock.Anything).
+		Return(io.NopCloser(bytes.NewReader([]byte("labels:\n  'bug':\n    include: ['\\bcrash\\b']\n  'docs':\n    include: ['\\bdocs\\b']\n"))), nil, nil)
+	mockClient.On("AddLabelsToIssue", mock.Anything, "owner", "repo", 1, []string{"bug", "docs"}).
+		Return([]*github.Label{{Name: ptr("bug")}, {Name: ptr("docs")}}, nil, nil)
+
+	assert.NoError(t, l.Execute())
+	mockClient.AssertExpectations(t)
+}
+
+func TestLabeler_retrieveConfig_local_config_directory(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "labeler.d")
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "nested"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "a.yml"), []byte("sync: true\nlabels:\n  'bug':\n    include: ['bug']\n"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "b.yaml"), []byte("labels:\n  'docs':\n    include: ['docs']\n"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "nested", "c.yml"), []byte("labels: ["), 0o644))
+
+	ctx := context.Background()
+	newLabeler := func() *Labeler {
+		return &Labeler{
+			Owner:        ptr("owner"),
+			Repo:         ptr("repo"),
+			context:      &ctx,
+			configPath:   "labeler.d/",
+			configSource: LocalConfigSource(root),
+		}
+	}
+
+	c, err := newLabeler().retrieveConfig()
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"bug", "docs"}, c.Rules().Names())
+	assert.True(t, c.(*model.FullConfig).Sync)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "c.yml"), []byte("labels:\n  'bug':\n    include: ['crash']\n"), 0o644))
+	_, err = newLabeler().retrieveConfig()
+	assert.EqualError(t, err, "could not merge the configs of \"labeler.d/\":\n"+
+		`line 2, column 3: /labels/bug: label "bug" is defined differently by "labeler.d/a.yml" and "labeler.d/c.yml"`)
+
+	_, err = (&Labeler{Owner: ptr("owner"), Repo: ptr("repo"), context: &ctx, configPath: "empty/", configSource: LocalConfigSource(root)}).retrieveConfig()
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func TestLabeler_retrieveConfig_local_config_directory_settings(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "labeler.d")
+	assert.NoError(t, os.MkdirAll(dir, 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "a.yml"), []byte("sync: true\nenable:\n  prs: false\n"), 0o644))
+
+	ctx := context.Background()
+	newLabeler := func() *Labeler {
+		return &Labeler{Owner: ptr("owner"), Repo: ptr("repo"), context: &ctx, configPath: "labeler.d/", configSource: LocalConfigSource(root)}
+	}
+
+	// only the merged config must define labels
+	_, err := newLabeler().retrieveConfig()
+	var problems model.ConfigErrors
+	if assert.ErrorAs(t, err, &problems) {
+		assert.Equal(t, model.ConfigErrors{
+			{File: "labeler.d/", Path: "/labels", Line: 1, Column: 1, Message: "full config requires labels to be defined"},
+		}, problems)
+	}
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "b.yml"), []byte("labels:\n  'bug':\n    include: ['bug']\n"), 0o644))
+	c, err := newLabeler().retrieveConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"bug"}, c.Rules().Names())
+	assert.True(t, c.(*model.FullConfig).Sync)
+	assert.False(t, c.(*model.FullConfig).Enable.PullRequestsEnabled())
+}
@@ -3,7 +3,10 @@ package labeler
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/jimschubert/labeler/model"
+	"io"
+	"net/http"
 	"os"
 	"strings"
 
@@ -18,13 +21,28 @@ type Opt struct {
 	token      string
 	ctx        context.Context
 	client     *github.Client
+	baseURL    string
+	uploadURL  string
 	owner      string
 	repo       string
 	event      string
 	id         int
 	data       string
 	configPath string
+	source     ConfigSource
+	configRef  string
 	fieldFlags FieldFlag
+	dryRun     bool
+	out        io.Writer
+	configs    *configCache
+	syncLabels bool
+	actions    bool
+
+	appID          int64
+	installationID int64
+	privateKey     []byte
+	privateKeyFile string
+	tokenSource    oauth2.TokenSource
 }
 
 type OptFn func(o *Opt)
@@ -38,6 +56,36 @@ func WithToken(value string) OptFn {
 	}
 }
 
+// WithAppID allows configuration of a GitHub App to authenticate as, instead of a token. Authenticating as an app
+// requires a private key (see WithPrivateKey or WithPrivateKeyFile); the installation is looked up from the owner and
+// repo, unless defined by WithInstallationID.
+func WithAppID(value int64) OptFn {
+	return func(o *Opt) {
+		o.appID = value
+	}
+}
+
+// WithInstallationID allows configuration of the GitHub App installation to authenticate as; use in conjunction with WithAppID
+func WithInstallationID(value int64) OptFn {
+	return func(o *Opt) {
+		o.installationID = value
+	}
+}
+
+// WithPrivateKey allows configuration of the PEM encoded private key of a GitHub App; use in conjunction with WithAppID
+func WithPrivateKey(value []byte) OptFn {
+	return func(o *Opt) {
+		o.privateKey = value
+	}
+}
+
+// WithPrivateKeyFile allows configuration of the path to the PEM encoded private key of a GitHub App; use in conjunction with WithAppID
+func WithPrivateKeyFile(value string) OptFn {
+	return func(o *Opt) {
+		o.privateKeyFile = value
+	}
+}
+
 // WithContext allows configuration of the context used as a parent context for all GitHub API calls
 func WithContext(ctx context.Context) OptFn {
 	return func(o *Opt) {
@@ -54,6 +102,22 @@ func WithClient(client *github.Client) OptFn {
 	}
 }
 
+// WithBaseURL allows configuration of the GitHub API URL, e.g. https://github.example.com/api/v3/ for GitHub Enterprise Server.
+// The GITHUB_API_URL environment variable is used by default; api.github.com is used if neither is defined.
+func WithBaseURL(value string) OptFn {
+	return func(o *Opt) {
+		o.baseURL = value
+	}
+}
+
+// WithUploadURL allows configuration of the GitHub upload URL, e.g. https://github.example.com/api/uploads/ for GitHub
+// Enterprise Server. If not defined, it is derived from the base URL (see WithBaseURL).
+func WithUploadURL(value string) OptFn {
+	return func(o *Opt) {
+		o.uploadURL = value
+	}
+}
+
 // WithOwner allows for configuring the user or organization owning the target repo
 func WithOwner(value string) OptFn {
 	return func(o *Opt) {
@@ -89,13 +153,30 @@ func WithData(value string) OptFn {
 	}
 }
 
-// WithConfigPath allows for configuring the labeler config path relative to the repository root (usually .github/labeler.yml)
+// WithConfigPath allows for configuring the labeler config path relative to the repository root (usually .github/labeler.yml).
+// A path ending with / is a directory of configs, which are merged (e.g. .github/labeler.d/).
 func WithConfigPath(value string) OptFn {
 	return func(o *Opt) {
 		o.configPath = value
 	}
 }
 
+// WithConfigSource allows for reading the config from somewhere other than the repository's default branch, such as the
+// local filesystem (see LocalConfigSource). The config is read from the configured path (see WithConfigPath).
+func WithConfigSource(source ConfigSource) OptFn {
+	return func(o *Opt) {
+		o.source = source
+	}
+}
+
+// WithConfigRef allows for reading the config at a git ref (a branch, tag or commit SHA) of the repository. Defaults to
+// the base commit of a pull request in the event payload (see WithData), or else the default branch.
+func WithConfigRef(value string) OptFn {
+	return func(o *Opt) {
+		o.configRef = value
+	}
+}
+
 // WithFields allows for configuring the fields to evaluate for labeling
 func WithFields(fieldFlag FieldFlag) OptFn {
 	return func(o *Opt) {
@@ -103,16 +184,65 @@ func WithFields(fieldFlag FieldFlag) OptFn {
 	}
 }
 
+// WithDryRun allows for evaluating labels without modifying the issue or pull request. Planned changes and explanations
+// of each label are written to the output (see WithOutput) instead.
+func WithDryRun(value bool) OptFn {
+	return func(o *Opt) {
+		o.dryRun = value
+	}
+}
+
+// WithOutput allows for configuring the destination of dry-run output (os.Stdout by default)
+func WithOutput(w io.Writer) OptFn {
+	return func(o *Opt) {
+		o.out = w
+	}
+}
+
+// WithSyncLabels allows for creating or updating repository labels to match their color and description in the config,
+// before they're added to an issue or pull request
+func WithSyncLabels(value bool) OptFn {
+	return func(o *Opt) {
+		o.syncLabels = value
+	}
+}
+
+// WithActions allows for reporting to GitHub Actions: the added and removed labels are written to the step outputs, the
+// evaluated labels to the job summary, and problems with the config as annotations. Enabled by default when the
+// GITHUB_ACTIONS environment variable is true.
+func WithActions(value bool) OptFn {
+	return func(o *Opt) {
+		o.actions = value
+	}
+}
+
+// withConfigCache allows for sharing parsed configs between Labelers (see Server)
+func withConfigCache(cache *configCache) OptFn {
+	return func(o *Opt) {
+		o.configs = cache
+	}
+}
+
+// withTokenSource allows for sharing credentials between Labelers (see Server)
+func withTokenSource(source oauth2.TokenSource) OptFn {
+	return func(o *Opt) {
+		o.tokenSource = source
+	}
+}
+
 // NewWithOptions constructs a new Labeler with functional arguments of type OptFn
 func NewWithOptions(opts ...OptFn) (*Labeler, error) {
 	l := Labeler{}
 	options := Opt{
 		token:      os.Getenv("GITHUB_TOKEN"),
+		baseURL:    os.Getenv("GITHUB_API_URL"),
 		owner:      os.Getenv("GITHUB_ACTOR"),
 		repo:       os.Getenv("GITHUB_REPO"),
 		event:      os.Getenv("GITHUB_EVENT_NAME"),
 		id:         -1,
 		fieldFlags: AllFieldFlags,
+		out:        os.Stdout,
+		actions:    os.Getenv("GITHUB_ACTIONS") == "true",
 	}
 
 	for _, opt := range opts {
@@ -144,19 +274,24 @@ func NewWithOptions(opts ...OptFn) (*Labeler, error) {
 		options.ctx = context.Background()
 	}
 
+	var limiter *rateLimiter
 	if options.client == nil {
-		// only validate the token when constructing this default client. Otherwise, assume the caller has property constructed a client
-		if options.token == "" && !skipTokenCheck {
-			return nil, errors.New("github token (e.g. GITHUB_TOKEN environment variable) is required")
+		// only validate credentials when constructing this default client. Otherwise, assume the caller has property constructed a client
+		source, err := options.credentials()
+		if err != nil {
+			return nil, err
 		}
 
-		options.client = github.NewClient(oauth2.NewClient(options.ctx, oauth2.StaticTokenSource(
-			&oauth2.Token{AccessToken: options.token},
-		)))
+		limiter = newRateLimiter()
+		base := context.WithValue(options.ctx, oauth2.HTTPClient, &http.Client{Transport: limiter.transport(nil)})
+		options.client, err = newGitHubClient(oauth2.NewClient(base, source), options.baseURL, options.uploadURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid GitHub API URL: %w", err)
+		}
 	}
 
 	if options.configPath == "" {
-		options.configPath = ".github/labeler.yml"
+		options.configPath = model.DefaultConfigPath
 	}
 
 	// assignment
@@ -171,10 +306,67 @@ func NewWithOptions(opts ...OptFn) (*Labeler, error) {
 		l.Data = &options.data
 	}
 	l.configPath = options.configPath
+	l.configSource = options.source
+	l.configRef = options.configRef
+	l.limiter = limiter
+	l.dryRun = options.dryRun
+	l.out = options.out
+	l.configs = options.configs
+	l.syncLabels = options.syncLabels
+	l.actions = options.actions
 
 	return &l, nil
 }
 
+// credentials returns the source of tokens for the default client: a GitHub App installation when an app ID is
+// configured, otherwise the static token
+func (o *Opt) credentials() (oauth2.TokenSource, error) {
+	if o.tokenSource != nil {
+		return o.tokenSource, nil
+	}
+
+	if o.appID != 0 {
+		if err := o.loadPrivateKey(); err != nil {
+			return nil, err
+		}
+		app := appCredentials{appID: o.appID, privateKey: o.privateKey, baseURL: o.baseURL, uploadURL: o.uploadURL}
+		return app.tokenSource(o.ctx, o.installationID, o.owner, o.repo)
+	}
+
+	if o.token == "" && !skipTokenCheck {
+		return nil, errors.New("github token (e.g. GITHUB_TOKEN environment variable) is required")
+	}
+	return oauth2.StaticTokenSource(&oauth2.Token{AccessToken: o.token}), nil
+}
+
+// loadPrivateKey reads the GitHub App private key from a file, if configured by path
+func (o *Opt) loadPrivateKey() error {
+	if o.privateKeyFile != "" {
+		b, err := os.ReadFile(o.privateKeyFile)
+		if err != nil {
+			return fmt.Errorf("could not read the GitHub App private key: %w", err)
+		}
+		o.privateKey = b
+	}
+	if len(o.privateKey) == 0 {
+		return errors.New("a private key is required to authenticate as a GitHub App")
+	}
+	return nil
+}
+
+// newGitHubClient constructs a client for api.github.com, or for GitHub Enterprise Server if a base URL is defined
+func newGitHubClient(httpClient *http.Client, baseURL, uploadURL string) (*github.Client, error) {
+	client := github.NewClient(httpClient)
+	if baseURL == "" || strings.TrimSuffix(baseURL, "/") == strings.TrimSuffix(client.BaseURL.String(), "/") {
+		return client, nil
+	}
+	if uploadURL == "" {
+		// GitHub Enterprise Server serves uploads from /api/uploads, alongside the API at /api/v3
+		uploadURL = strings.Replace(baseURL, "/api/v3", "/api/uploads", 1)
+	}
+	return github.NewEnterpriseClient(baseURL, uploadURL, httpClient)
+}
+
 // New creates a new instance of a Labeler
 func New(owner string, repo string, event string, id int, data *string) (*Labeler, error) {
 	if data == nil {
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/jimschubert/labeler/model"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -158,6 +159,7 @@ func TestNewWithOptions(t *testing.T) {
 
 					// optional fields
 					WithContext(childContext), WithConfigPath(".github/labeler-custom.yml"), WithData("{}"), WithToken("irrelevant"), WithFields(AllFieldFlags),
+					WithConfigSource(LocalConfigSource("workspace")),
 				},
 			},
 			validate: func(l *Labeler) {
@@ -171,9 +173,57 @@ func TestNewWithOptions(t *testing.T) {
 				assert.NotNil(t, l.client, "Should have created a default github client")
 
 				assert.Equal(t, ".github/labeler-custom.yml", l.configPath)
+				assert.Equal(t, LocalConfigSource("workspace"), l.configSource)
 				assert.Equal(t, &childContext, l.context)
 			},
 		},
+
+		{
+			name: "constructs an enterprise client when provided a base url",
+			args: args{
+				opts: []OptFn{WithOwner("jimschubert"), WithRepo("example"), WithID(1000), WithBaseURL("https://github.example.com/api/v3")},
+			},
+			validate: func(l *Labeler) {
+				client := l.client.(*model.RichClient).Client
+				assert.Equal(t, "https://github.example.com/api/v3/", client.BaseURL.String())
+				assert.Equal(t, "https://github.example.com/api/uploads/", client.UploadURL.String())
+			},
+		},
+
+		{
+			name: "constructs an enterprise client when provided a base and upload url",
+			args: args{
+				opts: []OptFn{
+					WithOwner("jimschubert"), WithRepo("example"), WithID(1000),
+					WithBaseURL("https://github.example.com/"), WithUploadURL("https://uploads.github.example.com/"),
+				},
+			},
+			validate: func(l *Labeler) {
+				client := l.client.(*model.RichClient).Client
+				assert.Equal(t, "https://github.example.com/api/v3/", client.BaseURL.String())
+				assert.Equal(t, "https://uploads.github.example.com/api/uploads/", client.UploadURL.String())
+			},
+		},
+
+		{
+			name: "constructs a default client when provided the api.github.com base url",
+			args: args{
+				opts: []OptFn{WithOwner("jimschubert"), WithRepo("example"), WithID(1000), WithBaseURL("https://api.github.com")},
+			},
+			validate: func(l *Labeler) {
+				client := l.client.(*model.RichClient).Client
+				assert.Equal(t, "https://api.github.com/", client.BaseURL.String())
+				assert.Equal(t, "https://uploads.github.com/", client.UploadURL.String())
+			},
+		},
+
+		{
+			name: "fails if the base url is invalid",
+			args: args{
+				opts: []OptFn{WithOwner("jimschubert"), WithRepo("example"), WithID(1000), WithBaseURL("://invalid")},
+			},
+			errorMatch: `invalid GitHub API URL: parse "://invalid": missing protocol scheme`,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -195,3 +245,12 @@ func TestNewWithOptions(t *testing.T) {
 		})
 	}
 }
+
+func TestNewWithOptions_base_url_from_environment(t *testing.T) {
+	t.Setenv("GITHUB_API_URL", "https://github.example.com/api/v3")
+	l, err := NewWithOptions(WithOwner("jimschubert"), WithRepo("example"), WithID(1000), WithToken("irrelevant"))
+	assert.NoError(t, err)
+
+	client := l.client.(*model.RichClient).Client
+	assert.Equal(t, "https://github.example.com/api/v3/", client.BaseURL.String())
+}
@@ -22,8 +22,11 @@ const (
 	FieldTitle FieldFlag = 1 << iota
 	// FieldBody indicates the body field should be evaluated for labeling.
 	FieldBody
+	// FieldHeadBranch indicates the head branch of a pull request should be evaluated for labeling. It isn't evaluated
+	// by default, and has no effect on issues.
+	FieldHeadBranch
 
-	// AllFieldFlags is a convenience constant representing all available fields.
+	// AllFieldFlags is a convenience constant representing the fields evaluated by default.
 	AllFieldFlags = FieldTitle | FieldBody
 )
 
@@ -37,6 +40,8 @@ func ParseFieldFlags(fields []string) FieldFlag {
 			flags |= FieldTitle
 		case "body", "description":
 			flags |= FieldBody
+		case "head_branch":
+			flags |= FieldHeadBranch
 		}
 	}
 	return flags
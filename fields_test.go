@@ -61,6 +61,8 @@ func TestParseFieldFlags(t *testing.T) {
 		{"Single body", []string{"body"}, FieldBody},
 		{"Single description (alternate)", []string{"description"}, FieldBody},
 		{"Both fields", []string{"title", "body"}, AllFieldFlags},
+		{"Head branch", []string{"head_branch"}, FieldHeadBranch},
+		{"All fields", []string{"title", "body", "head_branch"}, AllFieldFlags | FieldHeadBranch},
 		{"Duplicate fields", []string{"title", "title"}, FieldTitle},
 		{"Unknown field", []string{"unknown"}, 0},
 		{"Mixed known and unknown", []string{"title", "unknown"}, FieldTitle},
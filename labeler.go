@@ -7,7 +7,10 @@ import (
 	"fmt"
 	"io"
 	"maps"
-	"regexp"
+	"os"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/go-github/v50/github"
@@ -19,8 +22,12 @@ var (
 	issue             = "issues"
 	pullRequest       = "pull_request"
 	pullRequestTarget = "pull_request_target"
+	issueComment      = "issue_comment"
 )
 
+// commentMarker prefixes every comment added by the labeler, identifying the comment to update or skip
+const commentMarker = "<!-- Labeler (https://github.com/jimschubert/labeler) -->"
+
 type githubEvent interface {
 	GetTitle() string
 	GetBody() string
@@ -28,142 +35,357 @@ type githubEvent interface {
 
 // Labeler is the container for the application entrypoint's logic
 type Labeler struct {
-	Owner      *string
-	Repo       *string
-	Event      *string
-	Data       *string
-	ID         *int
-	context    *context.Context
-	client     model.Client
-	config     model.Config
-	configPath string
-	fieldFlag  FieldFlag
+	Owner        *string
+	Repo         *string
+	Event        *string
+	Data         *string
+	ID           *int
+	context      *context.Context
+	client       model.Client
+	config       model.Config
+	configPath   string
+	configSource ConfigSource
+	configRef    string
+	fieldFlag    FieldFlag
+	dryRun       bool
+	out          io.Writer
+	files        []*github.CommitFile
+	limiter      *rateLimiter
+	configs      *configCache
+	syncLabels   bool
+	actions      bool
 }
 
 // Execute performs the labeler logic
 func (l *Labeler) Execute() error {
+	ctx := context.Background()
+	if l.context != nil {
+		ctx = *l.context
+	}
+	_, err := l.ExecuteWithResult(ctx)
+	return err
+}
+
+// ExecuteWithResult performs the labeler logic within ctx, describing the labels which were evaluated, added, skipped
+// and removed, and any comment posted. When an error occurs after the issue or pull request was evaluated, the result
+// describes the changes made before the error. When running in GitHub Actions (see WithActions), the result is also
+// written to the step outputs and job summary.
+func (l *Labeler) ExecuteWithResult(ctx context.Context) (*Result, error) {
+	l.context = &ctx
+	result, err := l.execute()
+	if l.actions {
+		err = errors.Join(err, l.reportToActions(result, err))
+	}
+	return result, err
+}
+
+// Explain evaluates the configured labels against the target issue or pull request, without modifying either.
+// Each explanation describes which field and pattern matched a label, or which rule suppressed it.
+func (l *Labeler) Explain() ([]model.Explanation, error) {
 	err := l.checkPreconditions()
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	c, err := l.retrieveConfig()
+	if err != nil {
+		return nil, err
+	}
+	l.config = c
+
+	switch *l.Event {
+	case issue, issueComment:
+		i, err := l.getIssue()
+		if err != nil {
+			return nil, err
+		}
+		return l.explain(i)
+	case pullRequestTarget, pullRequest:
+		pr, err := l.getPullRequest()
+		if err != nil {
+			return nil, err
+		}
+		return l.explain(pr)
+	}
+
+	return nil, nil
+}
+
+// execute evaluates and labels the issue or pull request, or runs the commands of a comment
+func (l *Labeler) execute() (*Result, error) {
+	err := l.checkPreconditions()
+	if err != nil {
+		return nil, err
 	}
 
 	log.Debugf("executing with owner=%s repo=%s event=%s", *l.Owner, *l.Repo, *l.Event)
 
 	c, err := l.retrieveConfig()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	l.config = c
 
+	if !l.eventEnabled() {
+		log.Infof("Skipping %s event: disabled by the 'enable' block of %q", *l.Event, l.configPath)
+		return l.newResult(), nil
+	}
+
 	switch *l.Event {
 	case issue:
 		return l.processIssue()
 	case pullRequestTarget, pullRequest:
 		return l.processPullRequest()
+	case issueComment:
+		return l.processComment()
 	}
 
-	return nil
+	return l.newResult(), nil
 }
 
+// retrieveConfig returns the cached config of the repository at the ref, if any, or reads and parses the config
 func (l *Labeler) retrieveConfig() (model.Config, error) {
+	ref := l.configRefOf()
+	key := configKeyAt(*l.Owner, *l.Repo, ref)
+	if c := l.configs.get(key); c != nil {
+		log.Debugf("Using cached config of %s", key)
+		return c, nil
+	}
+
+	c, inherited, err := l.loadConfig(ref)
+	if err != nil {
+		return nil, err
+	}
+	l.configs.put(key, c, inherited...)
+	return c, nil
+}
+
+// configRefOf returns the git ref to read the config from: the configured ref, or else the base commit of a pull request
+// in the event payload. An empty ref reads the config from the default branch, or from a source other than the repository.
+func (l *Labeler) configRefOf() string {
+	if l.configSource != nil {
+		return ""
+	}
+	if l.configRef != "" {
+		return l.configRef
+	}
+	if l.Data == nil || l.Event == nil || (*l.Event != pullRequest && *l.Event != pullRequestTarget) {
+		return ""
+	}
+	pr, err := l.getPullRequest()
+	if err != nil {
+		return ""
+	}
+	return pr.GetBase().GetSHA()
+}
+
+// loadConfig reads the config from its source, the repository at ref by default, and parses it as either schema. A
+// config which extends another is merged onto it. The configs inherited from, either by extending them or by falling
+// back to the owner's .github repository, are returned along with the config.
+func (l *Labeler) loadConfig(ref string) (model.Config, []model.ConfigReference, error) {
 	if l.configPath == "" {
-		return nil, errors.New("the labeler configuration path can not be empty")
+		return nil, nil, errors.New("the labeler configuration path can not be empty")
 	}
 	ctx, cancel := context.WithTimeout(*l.context, 10*time.Second)
 	defer cancel()
 
-	r, _, err := l.client.DownloadContents(ctx, *l.Owner, *l.Repo, l.configPath, &github.RepositoryContentGetOptions{})
+	source := l.configSource
+	switch {
+	case source != nil:
+		if l.configRef != "" {
+			log.Warnf("Ignoring config ref %s, as the config isn't read from the repository", l.configRef)
+		}
+		log.Infof("Reading config %q", l.configPath)
+	case ref == "":
+		source = repositoryConfigSource{client: l.client, owner: *l.Owner, repo: *l.Repo}
+		log.Infof("Reading config %q from the default branch of %s/%s", l.configPath, *l.Owner, *l.Repo)
+	default:
+		source = repositoryConfigSource{client: l.client, owner: *l.Owner, repo: *l.Repo, ref: ref}
+		log.Infof("Reading config %q of %s/%s at ref %s", l.configPath, *l.Owner, *l.Repo, ref)
+	}
+	b, inherited, err := l.readConfig(ctx, source)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	defer r.Close()
 
-	bytes, err := io.ReadAll(r)
-	if err != nil {
-		return nil, fmt.Errorf("error reading %q: %w", l.configPath, err)
+	if model.ExtendsOf(b) != "" {
+		// problems of the config itself are reported at their own lines, rather than those of the merged config
+		if _, err := parseConfig(b, l.configPath); err != nil {
+			return nil, nil, err
+		}
+		origin := model.ConfigReference{Owner: *l.Owner, Repo: *l.Repo, Path: l.configPath, Ref: ref}
+		var extended []model.ConfigReference
+		if b, extended, err = l.extend(ctx, b, []string{origin.String()}); err != nil {
+			return nil, nil, err
+		}
+		inherited = append(inherited, extended...)
 	}
+	c, err := parseConfig(b, l.configPath)
+	return c, inherited, err
+}
 
-	var c model.Config
-	c = &model.FullConfig{}
-	if err = c.FromBytes(bytes); err == nil {
-		log.WithFields(log.Fields{l.configPath: c}).Debugf("Parsed %q as FullConfig", l.configPath)
-		return c, nil
+// parseConfig parses config bytes as the schema declared by their kind key, or else detected from their structure,
+// naming the config in errors. Every problem with the config is reported with its location (see model.ParseConfig),
+// and the name of the config as its file.
+func parseConfig(b []byte, name string) (model.Config, error) {
+	return parseConfigWith(model.ParseConfig, b, name)
+}
+
+// parseConfigWith parses config bytes with parse, such as model.ParseConfigFile, naming the config in errors as
+// parseConfig does
+func parseConfigWith(parse func([]byte) (model.Config, error), b []byte, name string) (model.Config, error) {
+	c, err := parse(b)
+	if err != nil {
+		var problems model.ConfigErrors
+		if errors.As(err, &problems) {
+			for i := range problems {
+				problems[i].File = name
+			}
+		}
+		return nil, fmt.Errorf("could not parse %q:\n%w", name, err)
 	}
+	log.WithFields(log.Fields{name: c}).Debugf("Parsed %q as %T", name, c)
+	return c, nil
+}
 
-	c = &model.SimpleConfig{}
-	if err = c.FromBytes(bytes); err == nil {
-		log.WithFields(log.Fields{l.configPath: c}).Debugf("Parsed %q as SimpleConfig", l.configPath)
-		return c, nil
+// eventEnabled determines whether the config's 'enable' block allows labeling the current event
+func (l *Labeler) eventEnabled() bool {
+	var enable *model.Enable
+	switch v := l.config.(type) {
+	case *model.FullConfig:
+		if v != nil {
+			enable = v.Enable
+		}
+	case *model.SimpleConfig:
+		if v != nil {
+			enable = v.Enable
+		}
 	}
 
-	return nil, fmt.Errorf("could not parse %q", l.configPath)
+	switch *l.Event {
+	case issue:
+		return enable.IssuesEnabled()
+	case pullRequestTarget, pullRequest:
+		return enable.PullRequestsEnabled()
+	case issueComment:
+		// a missing or invalid payload is reported when processing the comment
+		event, err := l.getCommentEvent()
+		if err != nil {
+			return true
+		}
+		if event.GetIssue().IsPullRequest() {
+			return enable.PullRequestsEnabled()
+		}
+		return enable.IssuesEnabled()
+	}
+	return true
 }
 
 func (l *Labeler) checkPreconditions() error {
+	if err := l.checkRepository(); err != nil {
+		return err
+	}
+	if *l.Event != issue && *l.Event != pullRequest && *l.Event != pullRequestTarget && *l.Event != issueComment {
+		return fmt.Errorf("event must be one of [ %s , %s , %s , %s ]", issue, pullRequest, pullRequestTarget, issueComment)
+	}
+
+	return nil
+}
+
+func (l *Labeler) checkRepository() error {
 	if len(*l.Owner) <= 1 {
 		return errors.New("owner is invalid")
 	}
 	if len(*l.Repo) <= 1 {
 		return errors.New("repo is invalid")
 	}
-	if *l.Event != issue && *l.Event != pullRequest && *l.Event != pullRequestTarget {
-		return fmt.Errorf("event must be one of [ %s , %s , %s ]", issue, pullRequest, pullRequestTarget)
-	}
-
 	return nil
 }
 
 // noinspection GoNilness
-func (l *Labeler) processIssue() error {
+func (l *Labeler) processIssue() (*Result, error) {
 	issue, err := l.getIssue()
 	if err != nil {
-		return err
+		return nil, err
 	}
+	return l.labelIssue(issue)
+}
 
-	count := l.applyLabels(issue, issue.Labels)
-	if count > 0 {
-		var comment *string
-		switch v := l.config.(type) {
-		case *model.FullConfig:
-			if v != nil && v.Comments != nil {
-				comment = v.Comments.Issues
-			}
-		case *model.SimpleConfig:
-			if v != nil {
-				comment = &v.Comment
-			}
-		}
-		if err := l.addComment(comment); err != nil {
-			return err
-		}
+func (l *Labeler) labelIssue(issue *github.Issue) (*Result, error) {
+	result, err := l.applyLabels(issue, issue.Labels)
+	if err != nil || len(result.Added) == 0 {
+		return result, err
 	}
-	return nil
+	if c, ok := l.config.(model.Commenter); ok {
+		result.Comment, err = l.addComment(c.IssueComment(), c.CommentStrategy(), issue, result.Added, result.Matches)
+	}
+	return result, err
 }
 
-func (l *Labeler) processPullRequest() error {
+func (l *Labeler) processPullRequest() (*Result, error) {
 	pr, err := l.getPullRequest()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	count := l.applyLabels(pr, pr.Labels)
-	if count > 0 {
-		var comment *string
-		switch v := l.config.(type) {
-		case *model.FullConfig:
-			if v != nil && v.Comments != nil {
-				comment = v.Comments.PullRequests
-			}
-		case *model.SimpleConfig:
-			if v != nil {
-				comment = &v.Comment
-			}
-		}
-		if err := l.addComment(comment); err != nil {
-			return err
-		}
+	result, err := l.applyLabels(pr, pr.Labels)
+	if err != nil || len(result.Added) == 0 {
+		return result, err
 	}
-	return nil
+	if c, ok := l.config.(model.Commenter); ok {
+		result.Comment, err = l.addComment(c.PullRequestComment(), c.CommentStrategy(), pr, result.Added, result.Matches)
+	}
+	return result, err
+}
+
+// newResult returns an empty result for the issue or pull request
+func (l *Labeler) newResult() *Result {
+	result := &Result{}
+	if l.Event != nil {
+		result.Event = *l.Event
+	}
+	if l.ID != nil {
+		result.Number = *l.ID
+	}
+	return result
+}
+
+// targetBranchOf returns the base branch of a pull request, or an empty string for other events
+func targetBranchOf(i githubEvent) string {
+	if pr, ok := i.(*github.PullRequest); ok && pr != nil && pr.Base != nil && pr.Base.Ref != nil {
+		return *pr.Base.Ref
+	}
+	return ""
+}
+
+// headBranchOf returns the head branch of a pull request, or an empty string for issues
+func headBranchOf(i githubEvent) string {
+	if pr, ok := i.(*github.PullRequest); ok && pr != nil {
+		return pr.GetHead().GetRef()
+	}
+	return ""
+}
+
+// definitionOf returns the definition of a label declared in the config, or an empty definition for a label which is
+// only derived from the title or size of a pull request
+func definitionOf(rules *model.RuleSet, name string) model.Label {
+	if rule := rules.Rule(name); rule != nil {
+		return rule.Label
+	}
+	return model.Label{}
+}
+
+// authorOf returns the user who opened the issue or pull request
+func authorOf(i githubEvent) model.Author {
+	var user *github.User
+	var association string
+	switch v := i.(type) {
+	case *github.Issue:
+		user, association = v.GetUser(), v.GetAuthorAssociation()
+	case *github.PullRequest:
+		user, association = v.GetUser(), v.GetAuthorAssociation()
+	}
+	return model.Author{Login: user.GetLogin(), Type: user.GetType(), Association: association}
 }
 
 func labelExists(s []*github.Label, name *string) bool {
@@ -177,83 +399,534 @@ func labelExists(s []*github.Label, name *string) bool {
 	return false
 }
 
-func (l *Labeler) addComment(comment *string) error {
-	if comment != nil && len(*comment) > 0 {
-		ctx, cancel := context.WithTimeout(*l.context, 10*time.Second)
-		defer cancel()
+// addComment renders the comment template for the labels applied to the issue or pull request and the patterns which
+// matched each label, returning the comment which was created or updated. A template which renders only whitespace
+// results in no comment. Unless the strategy is CommentStrategyCreate, the labeler's previous comment is either edited
+// in place or left as the only comment.
+func (l *Labeler) addComment(
+	comment *model.CommentTemplate, strategy model.CommentStrategy, i githubEvent, labels []string, matches map[string][]model.Match,
+) (*github.IssueComment, error) {
+	if comment == nil {
+		return nil, nil
+	}
+	body, err := comment.Render(l.commentData(i, labels, matches))
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(body) == "" {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(*l.context, 10*time.Second)
+	defer cancel()
 
-		issueComment := &github.IssueComment{
-			Body: newComment(*comment),
+	var previous *github.IssueComment
+	if strategy == model.CommentStrategyUpdate || strategy == model.CommentStrategyOnce {
+		previous, err = l.previousComment(ctx)
+		if err != nil {
+			return nil, err
 		}
-		_, _, err := l.client.CreateComment(ctx, *l.Owner, *l.Repo, *l.ID, issueComment)
-		return err
 	}
-	return nil
+
+	issueComment := &github.IssueComment{
+		Body: newComment(body),
+	}
+	switch {
+	case previous != nil && strategy == model.CommentStrategyOnce:
+		log.Debugf("Not commenting on #%d, which already has comment %d", *l.ID, previous.GetID())
+		return nil, nil
+	case previous != nil:
+		if previous.GetBody() == issueComment.GetBody() {
+			log.Debugf("Comment %d on #%d is unchanged", previous.GetID(), *l.ID)
+			return nil, nil
+		}
+		if l.dryRun {
+			_, _ = fmt.Fprintf(l.writer(), "dry-run: would update comment %d on #%d:\n%s\n", previous.GetID(), *l.ID, body)
+			issueComment.ID = previous.ID
+			return issueComment, nil
+		}
+		updated, _, err := l.client.EditComment(ctx, *l.Owner, *l.Repo, previous.GetID(), issueComment)
+		if err != nil {
+			return nil, fmt.Errorf("unable to update comment %d: %w", previous.GetID(), err)
+		}
+		return updated, nil
+	}
+
+	if l.dryRun {
+		_, _ = fmt.Fprintf(l.writer(), "dry-run: would comment on #%d:\n%s\n", *l.ID, body)
+		return issueComment, nil
+	}
+	created, _, err := l.client.CreateComment(ctx, *l.Owner, *l.Repo, *l.ID, issueComment)
+	if err != nil {
+		return nil, fmt.Errorf("unable to comment: %w", err)
+	}
+	return created, nil
+}
+
+// previousComment finds the most recent comment identified by the labeler's marker and authored by the labeler, or nil
+// if there is none. Other users may copy the marker, so a comment must be authored by the authenticated user; a GitHub
+// App installation can't look itself up, so any bot's comment is accepted instead.
+func (l *Labeler) previousComment(ctx context.Context) (*github.IssueComment, error) {
+	comments, _, err := l.client.ListComments(ctx, *l.Owner, *l.Repo, *l.ID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list comments: %w", err)
+	}
+	authored := func(comment *github.IssueComment) bool {
+		return comment.GetUser().GetType() == "Bot"
+	}
+	if user, _, err := l.client.GetAuthenticatedUser(ctx); err == nil && user.GetLogin() != "" {
+		authored = func(comment *github.IssueComment) bool {
+			return strings.EqualFold(comment.GetUser().GetLogin(), user.GetLogin())
+		}
+	} else if err != nil {
+		log.WithError(err).Debug("Unable to retrieve the authenticated user; expecting the previous comment from a bot.")
+	}
+	for idx := len(comments) - 1; idx >= 0; idx-- {
+		if strings.HasPrefix(comments[idx].GetBody(), commentMarker) && authored(comments[idx]) {
+			return comments[idx], nil
+		}
+	}
+	return nil, nil
+}
+
+// commentData describes the labels applied to the issue or pull request, and the patterns which matched each label
+func (l *Labeler) commentData(i githubEvent, labels []string, matches map[string][]model.Match) model.CommentData {
+	data := model.CommentData{Author: authorOf(i).Login, Number: *l.ID}
+	if l.Event != nil {
+		data.Event = *l.Event
+	}
+
+	names := append([]string(nil), labels...)
+	sort.Strings(names)
+	for _, name := range names {
+		data.Labels = append(data.Labels, model.CommentLabel{Name: name, Matches: matches[name]})
+	}
+	return data
 }
 
 func newComment(comment string) *string {
-	fullComment := fmt.Sprintf("<!-- Labeler (https://github.com/jimschubert/labeler) -->\n%s", comment)
+	fullComment := fmt.Sprintf("%s\n%s", commentMarker, comment)
 	return &fullComment
 }
 
-func (l *Labeler) applyLabels(i githubEvent, existingLabels []*github.Label) int {
-	targetBranch := ""
-	if pr, ok := i.(*github.PullRequest); ok && pr != nil && pr.Base != nil && pr.Base.Ref != nil {
-		targetBranch = *pr.Base.Ref
+// applyLabels adds the labels matching the issue or pull request, describing the labels which were added or skipped.
+// Labels which no longer apply are removed in sync mode, along with a previous size label. The patterns which matched
+// each label are only explained when they're reported: in dry-run mode, to GitHub Actions, or by a comment.
+func (l *Labeler) applyLabels(i githubEvent, existingLabels []*github.Label) (*Result, error) {
+	var explanations []model.Explanation
+	if l.dryRun || l.actions || l.commentFor(i) != nil {
+		var err error
+		if explanations, err = l.explain(i); err != nil {
+			return nil, err
+		}
+	}
+	if l.dryRun {
+		for _, explanation := range explanations {
+			_, _ = fmt.Fprintln(l.writer(), explanation)
+		}
+	}
+
+	fields := l.fieldsFor(i)
+	text := make([]string, 0, len(fields))
+	for _, f := range fields {
+		text = append(text, f.Text)
+	}
+
+	rules := l.config.Rules()
+	author := authorOf(i)
+	headBranch := headBranchOf(i)
+	labels := l.config.LabelsFor(text...)
+	maps.Copy(labels, rules.LabelsForAuthor(author))
+	maps.Copy(labels, rules.LabelsForHeadBranch(headBranch))
+	if pr, ok := i.(*github.PullRequest); ok && pr != nil {
+		fileLabels, err := l.labelsForChangedFiles()
+		if err != nil {
+			return nil, err
+		}
+		maps.Copy(labels, fileLabels)
+	}
+
+	for name := range l.titleMatches(i) {
+		if _, ok := labels[name]; !ok {
+			labels[name] = definitionOf(rules, name)
+		}
+	}
+
+	size, sizeLabel, _, err := l.sizeOf(i)
+	if err != nil {
+		return nil, err
+	}
+	if sizeLabel != "" {
+		labels[sizeLabel] = definitionOf(rules, sizeLabel)
+	}
+
+	result := l.newResult()
+	for _, explanation := range explanations {
+		if _, ok := labels[explanation.Label]; ok && len(explanation.Matches) > 0 {
+			if result.Matches == nil {
+				result.Matches = make(map[string][]model.Match)
+			}
+			result.Matches[explanation.Label] = explanation.Matches
+		}
+	}
+
+	targetBranch := targetBranchOf(i)
+	filteredLabels := make(map[string]model.Label)
+	newLabels := make([]string, 0, len(labels))
+	for _, name := range slices.Sorted(maps.Keys(labels)) {
+		result.Evaluated = append(result.Evaluated, name)
+		if reason := suppressionOf(rules.Rule(name), author, headBranch, targetBranch); reason != "" {
+			result.skip(name, reason)
+			continue
+		}
+		filteredLabels[name] = labels[name]
+		if labelExists(existingLabels, &name) {
+			result.skip(name, "already applied")
+			continue
+		}
+		newLabels = append(newLabels, name)
+	}
+
+	var errs []error
+	if fc, ok := l.config.(*model.FullConfig); ok && fc != nil && fc.Sync {
+		removed, err := l.removeStaleLabels(fc.ManagedLabels(), filteredLabels, existingLabels)
+		result.Removed = append(result.Removed, removed...)
+		errs = append(errs, err)
+	}
+	if size != nil {
+		// a pull request has a single size, so a previous size label is replaced
+		removed, err := l.removeStaleLabels(size.Labels(), filteredLabels, existingLabels)
+		result.Removed = append(result.Removed, removed...)
+		errs = append(errs, err)
+	}
+
+	if len(newLabels) == 0 {
+		log.Debug("Found 0 labels to apply")
+		return result, errors.Join(errs...)
+	}
+
+	if l.syncLabels {
+		// labels are added regardless, albeit without their configured color or description
+		errs = append(errs, l.syncLabelDefinitions(newLabels))
+	}
+	added, err := l.addLabels(newLabels, existingLabels)
+	result.Added = added
+	errs = append(errs, err)
+	return result, errors.Join(errs...)
+}
+
+// commentFor returns the comment template of the config for the issue or pull request, or nil if there is none
+func (l *Labeler) commentFor(i githubEvent) *model.CommentTemplate {
+	c, ok := l.config.(model.Commenter)
+	if !ok {
+		return nil
+	}
+	if _, ok := i.(*github.PullRequest); ok {
+		return c.PullRequestComment()
+	}
+	return c.IssueComment()
+}
+
+// explain describes how each label is evaluated against the issue or pull request, including file, author and branch rules
+func (l *Labeler) explain(i githubEvent) ([]model.Explanation, error) {
+	explainer, ok := l.config.(model.Explainer)
+	if !ok {
+		return nil, errors.New("the labeler configuration does not support explanations")
+	}
+	explanations := explainer.Explain(l.fieldsFor(i)...)
+
+	rules := l.config.Rules()
+	if pr, ok := i.(*github.PullRequest); ok && pr != nil && rules.HasFileRules() {
+		files, err := l.changedFiles()
+		if err != nil {
+			return nil, err
+		}
+		for idx, explanation := range explanations {
+			if m := rules.Rule(explanation.Label).MatchFiles(files...); m != nil {
+				explanations[idx].Matches = append(explanations[idx].Matches, *m)
+			}
+		}
+	}
+
+	author := authorOf(i)
+	headBranch := headBranchOf(i)
+	authorLabels := rules.LabelsForAuthor(author)
+	headBranchLabels := rules.LabelsForHeadBranch(headBranch)
+	for idx, explanation := range explanations {
+		rule := rules.Rule(explanation.Label)
+		// an author or head branch only matches by itself when the label has no patterns or file rules
+		if m := rule.MatchAuthor(author); m != nil {
+			if _, ok := authorLabels[explanation.Label]; ok || len(explanation.Matches) > 0 {
+				explanations[idx].Matches = append(explanations[idx].Matches, *m)
+			}
+		}
+		if m := rule.MatchHeadBranch(headBranch); m != nil {
+			if _, ok := headBranchLabels[explanation.Label]; ok || len(explanation.Matches) > 0 {
+				explanations[idx].Matches = append(explanations[idx].Matches, *m)
+			}
+		}
+	}
+
+	derived := l.titleMatches(i)
+	if derived == nil {
+		derived = make(map[string][]model.Match)
+	}
+	_, sizeLabel, sizeMatch, err := l.sizeOf(i)
+	if err != nil {
+		return nil, err
+	}
+	if sizeLabel != "" {
+		derived[sizeLabel] = append(derived[sizeLabel], *sizeMatch)
+	}
+
+	for idx, explanation := range explanations {
+		if matches, ok := derived[explanation.Label]; ok {
+			explanations[idx].Matches = append(explanations[idx].Matches, matches...)
+			delete(derived, explanation.Label)
+		}
+	}
+	unruled := make([]string, 0, len(derived))
+	for name := range derived {
+		unruled = append(unruled, name)
+	}
+	sort.Strings(unruled)
+	for _, name := range unruled {
+		explanations = append(explanations, model.Explanation{Label: name, Matches: derived[name]})
 	}
 
+	targetBranch := targetBranchOf(i)
+	for idx, explanation := range explanations {
+		if explanation.Applied() {
+			explanations[idx].Suppressed = suppressionOf(rules.Rule(explanation.Label), author, headBranch, targetBranch)
+		}
+	}
+
+	return explanations, nil
+}
+
+// suppressionOf describes why the author, head branch or target branch rules of a matching label prevent it from being
+// applied, or returns an empty string if the label is applied
+func suppressionOf(rule *model.Rule, author model.Author, headBranch, targetBranch string) string {
+	if !rule.AuthorAllowed(author) {
+		if rule.AuthorExcluded(author) {
+			return fmt.Sprintf("author %q is excluded by authors %v", author, rule.Label.Authors.Exclude)
+		}
+		return fmt.Sprintf("author %q does not match authors %v", author, rule.Label.Authors.Include)
+	}
+	if !rule.HeadBranchAllowed(headBranch) {
+		if headBranch == "" {
+			return fmt.Sprintf("restricted to head branches %v, but there is no head branch", rule.Label.HeadBranches)
+		}
+		return fmt.Sprintf("head branch %q does not match headBranches %v", headBranch, rule.Label.HeadBranches)
+	}
+	if !rule.BranchAllowed(targetBranch) {
+		if targetBranch == "" {
+			return fmt.Sprintf("restricted to branches %v, but there is no target branch", rule.Label.Branches)
+		}
+		return fmt.Sprintf("target branch %q does not match branches %v", targetBranch, rule.Label.Branches)
+	}
+	return ""
+}
+
+// titleMatches derives labels from the structure of a pull request title, such as a Conventional Commits title, if
+// the config supports it. Issues are not evaluated.
+func (l *Labeler) titleMatches(i githubEvent) map[string][]model.Match {
+	matcher, ok := l.config.(model.TitleMatcher)
+	if !ok {
+		return nil
+	}
+	if pr, ok := i.(*github.PullRequest); !ok || pr == nil {
+		return nil
+	}
+	matches, ok := matcher.MatchTitle(i.GetTitle())
+	if !ok {
+		log.Debugf("Unable to parse the title of #%d as a conventional commit: %q", *l.ID, i.GetTitle())
+	}
+	return matches
+}
+
+// fieldsFor returns the named fields of the issue or pull request to evaluate, honoring any fields defined by the config
+func (l *Labeler) fieldsFor(i githubEvent) []model.Field {
 	flags := l.fieldFlag.OrDefault()
-	fields := make([]string, 0)
+	fields := make([]model.Field, 0)
 
 	if overrideFields, ok := l.config.(*model.FullConfig); ok && len(overrideFields.Fields) > 0 {
 		flags = ParseFieldFlags(overrideFields.Fields)
 	}
 
 	if flags.Has(FieldTitle) {
-		fields = append(fields, i.GetTitle())
+		fields = append(fields, model.Field{Name: "title", Text: i.GetTitle()})
 	}
 
 	if flags.Has(FieldBody) {
-		fields = append(fields, i.GetBody())
+		fields = append(fields, model.Field{Name: "body", Text: i.GetBody()})
 	}
 
-	labels := l.config.LabelsFor(fields...)
-	filteredLabels := make(map[string]model.Label)
-	for name, label := range labels {
-		if len(label.Branches) > 0 && targetBranch != "" {
-			for _, branch := range label.Branches {
-				re := regexp.MustCompile(branch)
-				if re.MatchString(targetBranch) {
-					filteredLabels[name] = label
-					break
-				}
-			}
-		} else if len(label.Branches) == 0 {
-			filteredLabels[name] = label
+	if headBranch := headBranchOf(i); flags.Has(FieldHeadBranch) && headBranch != "" {
+		fields = append(fields, model.Field{Name: "head_branch", Text: headBranch})
+	}
+
+	return fields
+}
+
+// writer returns the destination for dry-run output
+func (l *Labeler) writer() io.Writer {
+	if l.out == nil {
+		return os.Stdout
+	}
+	return l.out
+}
+
+// removeStaleLabels removes existing labels which are managed by the config but are no longer desired, returning the
+// labels which were removed. Labels not declared in the config are never removed.
+func (l *Labeler) removeStaleLabels(managed []string, desired map[string]model.Label, existingLabels []*github.Label) ([]string, error) {
+	stale := make([]string, 0, len(managed))
+	for _, name := range managed {
+		if _, ok := desired[name]; !ok {
+			stale = append(stale, name)
 		}
 	}
+	return l.removeLabels(stale, existingLabels)
+}
 
-	newLabels := make([]string, 0, len(filteredLabels))
-	for name := range maps.Keys(filteredLabels) {
-		if !labelExists(existingLabels, &name) {
-			newLabels = append(newLabels, name)
+// addLabels adds the labels which aren't already applied, returning the labels which were added in lexical order
+func (l *Labeler) addLabels(names []string, existing []*github.Label) ([]string, error) {
+	added := make([]string, 0, len(names))
+	for _, name := range names {
+		if !labelExists(existing, &name) && !slices.Contains(added, name) {
+			added = append(added, name)
 		}
 	}
+	if len(added) == 0 {
+		return nil, nil
+	}
+	sort.Strings(added)
+
+	if l.dryRun {
+		_, _ = fmt.Fprintf(l.writer(), "dry-run: would add labels %v to #%d\n", added, *l.ID)
+		return added, nil
+	}
+
+	ctx, cancel := context.WithTimeout(*l.context, 10*time.Second)
+	defer cancel()
+	if _, _, err := l.client.AddLabelsToIssue(ctx, *l.Owner, *l.Repo, *l.ID, added); err != nil {
+		return nil, fmt.Errorf("unable to add labels %v: %w", added, err)
+	}
+	log.Debugf("Added labels %v", added)
+	return added, nil
+}
+
+// removeLabels removes the labels which are currently applied, returning the labels which were removed. A failure to
+// remove one label doesn't prevent the removal of others.
+func (l *Labeler) removeLabels(names []string, existing []*github.Label) ([]string, error) {
+	var errs []error
+	var removed []string
+	for _, name := range names {
+		if !labelExists(existing, &name) || slices.Contains(removed, name) {
+			continue
+		}
+
+		if l.dryRun {
+			_, _ = fmt.Fprintf(l.writer(), "dry-run: would remove label %q from #%d\n", name, *l.ID)
+			removed = append(removed, name)
+			continue
+		}
 
-	if len(newLabels) > 0 {
 		ctx, cancel := context.WithTimeout(*l.context, 10*time.Second)
-		defer cancel()
-		added, _, err := l.client.AddLabelsToIssue(ctx, *l.Owner, *l.Repo, *l.ID, newLabels)
+		_, err := l.client.RemoveLabelForIssue(ctx, *l.Owner, *l.Repo, *l.ID, name)
+		cancel()
 		if err != nil {
-			log.WithFields(log.Fields{"err": err}).Error("Unable to add labels to issue.")
-			return 0
+			errs = append(errs, fmt.Errorf("unable to remove label %q: %w", name, err))
+			continue
+		}
+		removed = append(removed, name)
+		log.Debugf("Removed label %q", name)
+	}
+	return removed, errors.Join(errs...)
+}
+
+// labelsForChangedFiles evaluates file rules against the files changed by the pull request.
+// Changed files are only requested when the config defines at least one file rule.
+func (l *Labeler) labelsForChangedFiles() (map[string]model.Label, error) {
+	rules := l.config.Rules()
+	if !rules.HasFileRules() {
+		return nil, nil
+	}
+
+	files, err := l.changedFiles()
+	if err != nil {
+		return nil, err
+	}
+	return rules.LabelsForFiles(files...), nil
+}
+
+// changedFiles lists the paths changed by the pull request, including the previous path of renamed files
+func (l *Labeler) changedFiles() ([]string, error) {
+	files, err := l.pullRequestFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(files))
+	for _, f := range files {
+		names = append(names, f.GetFilename())
+		if previous := f.GetPreviousFilename(); previous != "" {
+			names = append(names, previous)
+		}
+	}
+	log.Debugf("Evaluating %d changed files", len(names))
+	return names, nil
+}
+
+// pullRequestFiles lists the files changed by the pull request. The result is retained, so the API is queried at most
+// once per Labeler.
+func (l *Labeler) pullRequestFiles() ([]*github.CommitFile, error) {
+	if l.files != nil {
+		return l.files, nil
+	}
+
+	ctx, cancel := context.WithTimeout(*l.context, 30*time.Second)
+	defer cancel()
+	files, _, err := l.client.ListPullRequestFiles(ctx, *l.Owner, *l.Repo, *l.ID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list pull request files: %w", err)
+	}
+	if files == nil {
+		files = make([]*github.CommitFile, 0)
+	}
+
+	l.files = files
+	return files, nil
+}
+
+// sizeOf determines the size label of a pull request from its changed lines, if the config defines size labels.
+// Changes to excluded files aren't counted, which requires listing the changed files.
+func (l *Labeler) sizeOf(i githubEvent) (*model.Size, string, *model.Match, error) {
+	fc, ok := l.config.(*model.FullConfig)
+	if !ok || fc == nil || fc.Size == nil {
+		return nil, "", nil, nil
+	}
+	pr, ok := i.(*github.PullRequest)
+	if !ok || pr == nil {
+		return nil, "", nil, nil
+	}
+
+	lines := pr.GetAdditions() + pr.GetDeletions()
+	if fc.Size.HasExclusions() {
+		files, err := l.pullRequestFiles()
+		if err != nil {
+			return nil, "", nil, err
+		}
+		lines = 0
+		for _, f := range files {
+			if !fc.Size.Excluded(f.GetFilename()) {
+				lines += f.GetAdditions() + f.GetDeletions()
+			}
 		}
-		log.Debugf("Found %d new labels to apply", len(added))
-		return len(added)
 	}
 
-	log.Debug("Found 0 labels to apply")
-	return 0
+	label, match := fc.Size.Label(lines)
+	return fc.Size, label, match, nil
 }
 
 func (l *Labeler) getPullRequest() (*github.PullRequest, error) {
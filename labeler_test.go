@@ -28,6 +28,11 @@ func (m *mockConfig) LabelsFor(text ...string) map[string]model.Label {
 	return args.Get(0).(map[string]model.Label)
 }
 
+func (m *mockConfig) Rules() *model.RuleSet {
+	rules, _ := model.NewRuleSet(nil)
+	return rules
+}
+
 func (m *mockConfig) FromBytes(b []byte) error {
 	args := m.Called(b)
 	return args.Error(0)
@@ -42,6 +47,12 @@ func (m *mockRichClient) DownloadContents(ctx context.Context, owner, repo, path
 	return args.Get(0).(io.ReadCloser), nil, args.Error(2)
 }
 
+func (m *mockRichClient) GetContents(ctx context.Context, owner, repo, path string, opts *github.RepositoryContentGetOptions) (
+	*github.RepositoryContent, []*github.RepositoryContent, *github.Response, error) {
+	args := m.Called(ctx, owner, repo, path, opts)
+	return nil, args.Get(0).([]*github.RepositoryContent), nil, args.Error(1)
+}
+
 func (m *mockRichClient) AddLabelsToIssue(ctx context.Context, owner, repo string, number int, labels []string) ([]*github.Label, *github.Response, error) {
 	args := m.Called(ctx, owner, repo, number, labels)
 	return args.Get(0).([]*github.Label), nil, args.Error(2)
@@ -49,7 +60,33 @@ func (m *mockRichClient) AddLabelsToIssue(ctx context.Context, owner, repo strin
 
 func (m *mockRichClient) CreateComment(ctx context.Context, owner, repo string, number int, comment *github.IssueComment) (*github.IssueComment, *github.Response, error) {
 	args := m.Called(ctx, owner, repo, number, comment)
-	return nil, nil, args.Error(2)
+	created, _ := args.Get(0).(*github.IssueComment)
+	return created, nil, args.Error(2)
+}
+
+func (m *mockRichClient) ListComments(ctx context.Context, owner, repo string, number int) ([]*github.IssueComment, *github.Response, error) {
+	args := m.Called(ctx, owner, repo, number)
+	return args.Get(0).([]*github.IssueComment), nil, args.Error(2)
+}
+
+func (m *mockRichClient) EditComment(ctx context.Context, owner, repo string, commentID int64, comment *github.IssueComment) (*github.IssueComment, *github.Response, error) {
+	args := m.Called(ctx, owner, repo, commentID, comment)
+	return comment, nil, args.Error(2)
+}
+
+func (m *mockRichClient) GetPermissionLevel(ctx context.Context, owner, repo, user string) (*github.RepositoryPermissionLevel, *github.Response, error) {
+	args := m.Called(ctx, owner, repo, user)
+	return args.Get(0).(*github.RepositoryPermissionLevel), nil, args.Error(2)
+}
+
+func (m *mockRichClient) GetAuthenticatedUser(ctx context.Context) (*github.User, *github.Response, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(*github.User), nil, args.Error(2)
+}
+
+func (m *mockRichClient) RemoveLabelForIssue(ctx context.Context, owner, repo string, number int, label string) (*github.Response, error) {
+	args := m.Called(ctx, owner, repo, number, label)
+	return nil, args.Error(1)
 }
 
 func (m *mockRichClient) GetIssue(ctx context.Context, owner, repo string, number int) (*github.Issue, *github.Response, error) {
@@ -62,6 +99,47 @@ func (m *mockRichClient) GetPullRequest(ctx context.Context, owner, repo string,
 	return args.Get(0).(*github.PullRequest), nil, args.Error(2)
 }
 
+func (m *mockRichClient) ListRepositoryIssues(ctx context.Context, owner, repo string, opts *github.IssueListByRepoOptions) ([]*github.Issue, *github.Response, error) {
+	args := m.Called(ctx, owner, repo, opts)
+	return args.Get(0).([]*github.Issue), nil, args.Error(2)
+}
+
+func (m *mockRichClient) ListPullRequestFiles(ctx context.Context, owner, repo string, number int) ([]*github.CommitFile, *github.Response, error) {
+	args := m.Called(ctx, owner, repo, number)
+	return args.Get(0).([]*github.CommitFile), nil, args.Error(2)
+}
+
+func (m *mockRichClient) ListLabels(ctx context.Context, owner, repo string) ([]*github.Label, *github.Response, error) {
+	args := m.Called(ctx, owner, repo)
+	return args.Get(0).([]*github.Label), nil, args.Error(2)
+}
+
+func (m *mockRichClient) CreateLabel(ctx context.Context, owner, repo string, label *github.Label) (*github.Label, *github.Response, error) {
+	args := m.Called(ctx, owner, repo, label)
+	return label, nil, args.Error(2)
+}
+
+func (m *mockRichClient) EditLabel(ctx context.Context, owner, repo, name string, label *github.Label) (*github.Label, *github.Response, error) {
+	args := m.Called(ctx, owner, repo, name, label)
+	return label, nil, args.Error(2)
+}
+
+// newTestLabeler constructs a Labeler of #1 of owner/repo for the event, whose config at .github/labeler.yml is
+// downloaded by the client
+func newTestLabeler(ctx *context.Context, client *mockRichClient, config string, event string) *Labeler {
+	client.On("DownloadContents", mock.Anything, "owner", "repo", ".github/labeler.yml", mock.Anything).
+		Return(io.NopCloser(bytes.NewReader([]byte(config))), nil, nil)
+	return &Labeler{
+		Owner:      ptr("owner"),
+		Repo:       ptr("repo"),
+		Event:      ptr(event),
+		ID:         ptr(1),
+		context:    ctx,
+		client:     client,
+		configPath: ".github/labeler.yml",
+	}
+}
+
 func TestLabeler_checkPreconditions(t *testing.T) {
 	l := &Labeler{
 		Owner: ptr("o"),
@@ -148,8 +226,9 @@ func TestLabeler_applyLabels(t *testing.T) {
 	mockClient.On("AddLabelsToIssue", mock.Anything, "owner", "repo", 1, []string{"bug"}).
 		Return([]*github.Label{{Name: ptr("bug")}}, nil, nil)
 	ev := &testEvent{title: "title", body: "body"}
-	count := l.applyLabels(ev, []*github.Label{})
-	assert.Equal(t, 1, count)
+	result, err := l.applyLabels(ev, []*github.Label{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"bug"}, result.Added)
 
 	mockClient.AssertNumberOfCalls(t, "AddLabelsToIssue", 1)
 	mockClient.AssertExpectations(t)
@@ -175,8 +254,9 @@ func TestLabeler_applyLabelsCustomizedFields(t *testing.T) {
 	mockClient.On("AddLabelsToIssue", mock.Anything, "owner", "repo", 1, []string{"bug"}).
 		Return([]*github.Label{{Name: ptr("bug")}}, nil, nil)
 	ev := &testEvent{title: "title", body: "body"}
-	count := l.applyLabels(ev, []*github.Label{})
-	assert.Equal(t, 1, count)
+	result, err := l.applyLabels(ev, []*github.Label{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"bug"}, result.Added)
 
 	mockClient.AssertNumberOfCalls(t, "AddLabelsToIssue", 1)
 	mockClient.AssertExpectations(t)
@@ -192,13 +272,115 @@ func TestLabeler_addComment(t *testing.T) {
 		context: &ctx,
 		client:  mockClient,
 	}
-	comment := "hello"
+	comment, err := model.NewCommentTemplate("hello")
+	assert.NoError(t, err)
 	mockClient.On("CreateComment", mock.Anything, "owner", "repo", 1, mock.Anything).
 		Return(nil, nil, nil)
-	err := l.addComment(&comment)
+	_, err = l.addComment(comment, model.CommentStrategyCreate, &github.Issue{}, []string{"bug"}, nil)
 	assert.NoError(t, err)
 }
 
+func TestLabeler_addComment_strategy(t *testing.T) {
+	marked := func(id int64, body string) *github.IssueComment {
+		return &github.IssueComment{ID: ptr(id), Body: newComment(body), User: &github.User{Login: ptr("labeler")}}
+	}
+	tests := []struct {
+		name       string
+		strategy   model.CommentStrategy
+		existing   []*github.IssueComment
+		anonymous  bool
+		wantCreate bool
+		wantEdit   int64
+	}{
+		{
+			name:       "create ignores previous comments",
+			strategy:   model.CommentStrategyCreate,
+			wantCreate: true,
+		},
+		{
+			name:       "update without a previous comment creates one",
+			strategy:   model.CommentStrategyUpdate,
+			existing:   []*github.IssueComment{{ID: ptr(int64(1)), Body: ptr("unrelated")}},
+			wantCreate: true,
+		},
+		{
+			name:     "update edits the most recent labeler comment",
+			strategy: model.CommentStrategyUpdate,
+			existing: []*github.IssueComment{marked(1, "old"), {ID: ptr(int64(2)), Body: ptr("unrelated")}, marked(3, "older")},
+			wantEdit: 3,
+		},
+		{
+			name:     "update ignores marked comments of other users",
+			strategy: model.CommentStrategyUpdate,
+			existing: []*github.IssueComment{
+				marked(1, "old"),
+				{ID: ptr(int64(2)), Body: newComment("copied"), User: &github.User{Login: ptr("someone"), Type: ptr("User")}},
+			},
+			wantEdit: 1,
+		},
+		{
+			name:      "update as an app edits the most recent bot comment",
+			strategy:  model.CommentStrategyUpdate,
+			anonymous: true,
+			existing: []*github.IssueComment{
+				{ID: ptr(int64(1)), Body: newComment("old"), User: &github.User{Login: ptr("labeler[bot]"), Type: ptr("Bot")}},
+				{ID: ptr(int64(2)), Body: newComment("copied"), User: &github.User{Login: ptr("someone"), Type: ptr("User")}},
+			},
+			wantEdit: 1,
+		},
+		{
+			name:     "update leaves an unchanged comment",
+			strategy: model.CommentStrategyUpdate,
+			existing: []*github.IssueComment{marked(1, "hello")},
+		},
+		{
+			name:       "once without a previous comment creates one",
+			strategy:   model.CommentStrategyOnce,
+			wantCreate: true,
+		},
+		{
+			name:     "once never comments again",
+			strategy: model.CommentStrategyOnce,
+			existing: []*github.IssueComment{marked(1, "old")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			mockClient := new(mockRichClient)
+			l := &Labeler{Owner: ptr("owner"), Repo: ptr("repo"), ID: ptr(1), context: &ctx, client: mockClient}
+
+			comment, err := model.NewCommentTemplate("hello")
+			assert.NoError(t, err)
+			mockClient.On("ListComments", mock.Anything, "owner", "repo", 1).Return(tt.existing, nil, nil)
+			if tt.anonymous {
+				mockClient.On("GetAuthenticatedUser", mock.Anything).Return((*github.User)(nil), nil, errors.New("resource not accessible by integration"))
+			} else {
+				mockClient.On("GetAuthenticatedUser", mock.Anything).Return(&github.User{Login: ptr("labeler")}, nil, nil)
+			}
+			mockClient.On("CreateComment", mock.Anything, "owner", "repo", 1, mock.Anything).Return(nil, nil, nil)
+			mockClient.On("EditComment", mock.Anything, "owner", "repo", mock.Anything, mock.Anything).Return(nil, nil, nil)
+
+			_, err = l.addComment(comment, tt.strategy, &github.Issue{}, []string{"bug"}, nil)
+			assert.NoError(t, err)
+
+			if tt.wantCreate {
+				mockClient.AssertCalled(t, "CreateComment", mock.Anything, "owner", "repo", 1, &github.IssueComment{Body: newComment("hello")})
+			} else {
+				mockClient.AssertNotCalled(t, "CreateComment", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+			if tt.wantEdit != 0 {
+				mockClient.AssertCalled(t, "EditComment", mock.Anything, "owner", "repo", tt.wantEdit, &github.IssueComment{Body: newComment("hello")})
+			} else {
+				mockClient.AssertNotCalled(t, "EditComment", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+			if tt.strategy == model.CommentStrategyCreate {
+				mockClient.AssertNotCalled(t, "ListComments", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
 func TestLabeler_getIssue_withData(t *testing.T) {
 	mockClient := new(mockRichClient)
 	l := &Labeler{
@@ -529,6 +711,715 @@ labels:
 	mockClient.AssertExpectations(t)
 }
 
+func TestLabeler_Execute_comment_template(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(mockRichClient)
+
+	l := &Labeler{
+		Owner:      ptr("owner"),
+		Repo:       ptr("repo"),
+		Event:      ptr("issues"),
+		ID:         ptr(1),
+		Data:       ptr(`{"issue":{"number":1,"title":"crash on help","user":{"login":"octocat"}}}`),
+		context:    &ctx,
+		client:     mockClient,
+		configPath: ".github/labeler.yml",
+	}
+	mockClient.On("DownloadContents", mock.Anything, "owner", "repo", ".github/labeler.yml", mock.Anything).
+		Return(io.NopCloser(bytes.NewReader([]byte(
+			`comments:
+  issues: |
+    Thanks @{{ .Author }}! Applied {{ join .Names ", " }} to #{{ .Number }} ({{ .Event }}).
+    {{- range .Labels }}
+    {{ .Name }}: {{ range .Matches }}{{ .Field }} mentions '{{ .Text }}'{{ end }}
+    {{- end }}
+
+labels:
+  'bug':
+    include: ['\bcrash\b']
+  'help wanted':
+    include: ['\bhelp\b']
+`))), nil, nil)
+	mockClient.On("AddLabelsToIssue", mock.Anything, "owner", "repo", 1, []string{"bug", "help wanted"}).
+		Return([]*github.Label{{Name: ptr("bug")}, {Name: ptr("help wanted")}}, nil, nil)
+	mockClient.On("CreateComment", mock.Anything, "owner", "repo", 1, mock.Anything).Return(nil, nil, nil)
+
+	assert.NoError(t, l.Execute())
+	mockClient.AssertExpectations(t)
+
+	comment := mockClient.Calls[len(mockClient.Calls)-1].Arguments.Get(4).(*github.IssueComment)
+	assert.Equal(t, `<!-- Labeler (https://github.com/jimschubert/labeler) -->
+Thanks @octocat! Applied bug, help wanted to #1 (issues).
+bug: title mentions 'crash'
+help wanted: title mentions 'help'
+`, comment.GetBody())
+}
+
+func TestLabeler_Execute_changed_files(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(mockRichClient)
+
+	l := &Labeler{
+		Owner:      ptr("owner"),
+		Repo:       ptr("repo"),
+		Event:      ptr("pull_request"),
+		ID:         ptr(1),
+		context:    &ctx,
+		client:     mockClient,
+		configPath: ".github/labeler.yml",
+	}
+	mockClient.On("DownloadContents", mock.Anything, "owner", "repo", ".github/labeler.yml", mock.Anything).
+		Return(io.NopCloser(bytes.NewReader([]byte(
+			`labels:
+  'area/docs':
+    files:
+      include:
+        - 'docs/**'
+  'area/cli':
+    files:
+      include:
+        - 'cmd/**'
+      exclude:
+        - 'cmd/main.go'
+    branches:
+      - main
+  'bug':
+    include:
+      - '\bbug[s]?\b'
+`))), nil, nil)
+	mockClient.On("GetPullRequest", mock.Anything, "owner", "repo", 1).
+		Return(&github.PullRequest{Title: ptr("update docs"), Body: ptr("b"), Base: &github.PullRequestBranch{Ref: ptr("develop")}}, nil, nil)
+	mockClient.On("ListPullRequestFiles", mock.Anything, "owner", "repo", 1).
+		Return([]*github.CommitFile{{Filename: ptr("docs/samples/full.yml")}, {Filename: ptr("cmd/labeler/root.go")}}, nil, nil)
+	mockClient.On("AddLabelsToIssue", mock.Anything, "owner", "repo", 1, []string{"area/docs"}).
+		Return([]*github.Label{{Name: ptr("area/docs")}}, nil, nil)
+
+	err := l.Execute()
+	assert.NoError(t, err)
+	mockClient.AssertNumberOfCalls(t, "ListPullRequestFiles", 1)
+	mockClient.AssertCalled(t, "AddLabelsToIssue", mock.Anything, "owner", "repo", 1, []string{"area/docs"})
+	mockClient.AssertExpectations(t)
+}
+
+func TestLabeler_Execute_conventional(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(mockRichClient)
+
+	l := &Labeler{
+		Owner:      ptr("owner"),
+		Repo:       ptr("repo"),
+		Event:      ptr("pull_request"),
+		ID:         ptr(1),
+		context:    &ctx,
+		client:     mockClient,
+		configPath: ".github/labeler.yml",
+	}
+	mockClient.On("DownloadContents", mock.Anything, "owner", "repo", ".github/labeler.yml", mock.Anything).
+		Return(io.NopCloser(bytes.NewReader([]byte(
+			`conventional:
+  types:
+    feat: enhancement
+  scopes:
+    api: area/api
+  breaking: breaking
+labels:
+  'breaking':
+    include: ['BREAKING CHANGE']
+    branches: ['main']
+  'bug':
+    include: ['\bbug[s]?\b']
+`))), nil, nil)
+	mockClient.On("GetPullRequest", mock.Anything, "owner", "repo", 1).
+		Return(&github.PullRequest{Title: ptr("feat(api)!: drop v1 endpoints"), Body: ptr("b"), Base: &github.PullRequestBranch{Ref: ptr("develop")}}, nil, nil)
+	mockClient.On("AddLabelsToIssue", mock.Anything, "owner", "repo", 1, []string{"area/api", "enhancement"}).
+		Return([]*github.Label{{Name: ptr("area/api")}, {Name: ptr("enhancement")}}, nil, nil)
+
+	err := l.Execute()
+	assert.NoError(t, err)
+	mockClient.AssertExpectations(t)
+
+	explanations, err := l.explain(&github.PullRequest{Title: ptr("feat(api)!: drop v1 endpoints"), Base: &github.PullRequestBranch{Ref: ptr("develop")}})
+	assert.NoError(t, err)
+	assert.Equal(t, []model.Explanation{
+		{
+			Label:      "breaking",
+			Matches:    []model.Match{{Field: "title", Pattern: "conventional breaking change", Text: "!", Start: 9, End: 10}},
+			Suppressed: `target branch "develop" does not match branches [main]`,
+		},
+		{Label: "bug"},
+		{
+			Label:   "area/api",
+			Matches: []model.Match{{Field: "title", Pattern: "conventional scope api", Text: "api", Start: 5, End: 8}},
+		},
+		{
+			Label:   "enhancement",
+			Matches: []model.Match{{Field: "title", Pattern: "conventional type feat", Text: "feat", Start: 0, End: 4}},
+		},
+	}, explanations, "branch restrictions of a declared label apply to conventional labels")
+}
+
+func TestLabeler_Execute_size(t *testing.T) {
+	labels := `labels:
+  'bug':
+    include: ['\bbug[s]?\b']
+`
+	tests := []struct {
+		name      string
+		config    string
+		existing  []*github.Label
+		wantAdd   []string
+		wantFiles bool
+	}{
+		{
+			name:     "replaces the previous size label",
+			config:   "size: {}\n" + labels,
+			existing: []*github.Label{{Name: ptr("size/S")}},
+			wantAdd:  []string{"size/L"},
+		},
+		{
+			name:      "excluded files aren't counted",
+			config:    "size:\n  exclude: ['go.sum']\n" + labels,
+			existing:  []*github.Label{{Name: ptr("size/S")}},
+			wantAdd:   []string{"size/M"},
+			wantFiles: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			mockClient := new(mockRichClient)
+			l := &Labeler{
+				Owner:      ptr("owner"),
+				Repo:       ptr("repo"),
+				Event:      ptr("pull_request"),
+				ID:         ptr(1),
+				context:    &ctx,
+				client:     mockClient,
+				configPath: ".github/labeler.yml",
+			}
+			mockClient.On("DownloadContents", mock.Anything, "owner", "repo", ".github/labeler.yml", mock.Anything).
+				Return(io.NopCloser(bytes.NewReader([]byte(tt.config))), nil, nil)
+			mockClient.On("GetPullRequest", mock.Anything, "owner", "repo", 1).
+				Return(&github.PullRequest{Title: ptr("grow"), Additions: ptr(120), Deletions: ptr(20), Labels: tt.existing}, nil, nil)
+			if tt.wantFiles {
+				mockClient.On("ListPullRequestFiles", mock.Anything, "owner", "repo", 1).
+					Return([]*github.CommitFile{
+						{Filename: ptr("go.sum"), Additions: ptr(100), Deletions: ptr(10)},
+						{Filename: ptr("main.go"), Additions: ptr(20), Deletions: ptr(10)},
+					}, nil, nil)
+			}
+			mockClient.On("AddLabelsToIssue", mock.Anything, "owner", "repo", 1, tt.wantAdd).
+				Return([]*github.Label{{Name: ptr(tt.wantAdd[0])}}, nil, nil)
+			mockClient.On("RemoveLabelForIssue", mock.Anything, "owner", "repo", 1, "size/S").Return(nil, nil)
+
+			assert.NoError(t, l.Execute())
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLabeler_Execute_authors(t *testing.T) {
+	config := `labels:
+  'dependencies':
+    authors:
+      include: ['dependabot[bot]']
+  'good first contribution':
+    authors:
+      include: ['association:FIRST_TIME_CONTRIBUTOR']
+  'bug':
+    include: ['\bbug[s]?\b']
+    authors:
+      exclude: ['type:bot']
+`
+	tests := []struct {
+		name    string
+		data    string
+		wantAdd []string
+	}{
+		{
+			name:    "bot",
+			data:    `{"issue":{"number":1,"title":"bump a bug fix","user":{"login":"dependabot[bot]","type":"Bot"},"author_association":"NONE"}}`,
+			wantAdd: []string{"dependencies"},
+		},
+		{
+			name:    "first time contributor",
+			data:    `{"issue":{"number":1,"title":"fix a bug","user":{"login":"octocat","type":"User"},"author_association":"FIRST_TIME_CONTRIBUTOR"}}`,
+			wantAdd: []string{"bug", "good first contribution"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			mockClient := new(mockRichClient)
+			l := &Labeler{
+				Owner:      ptr("owner"),
+				Repo:       ptr("repo"),
+				Event:      ptr("issues"),
+				ID:         ptr(1),
+				Data:       ptr(tt.data),
+				context:    &ctx,
+				client:     mockClient,
+				configPath: ".github/labeler.yml",
+			}
+			mockClient.On("DownloadContents", mock.Anything, "owner", "repo", ".github/labeler.yml", mock.Anything).
+				Return(io.NopCloser(bytes.NewReader([]byte(config))), nil, nil)
+			mockClient.On("AddLabelsToIssue", mock.Anything, "owner", "repo", 1, tt.wantAdd).
+				Return([]*github.Label{}, nil, nil)
+
+			assert.NoError(t, l.Execute())
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLabeler_Execute_head_branches(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(mockRichClient)
+
+	l := &Labeler{
+		Owner:      ptr("owner"),
+		Repo:       ptr("repo"),
+		Event:      ptr("pull_request"),
+		ID:         ptr(1),
+		context:    &ctx,
+		client:     mockClient,
+		configPath: ".github/labeler.yml",
+	}
+	mockClient.On("DownloadContents", mock.Anything, "owner", "repo", ".github/labeler.yml", mock.Anything).
+		Return(io.NopCloser(bytes.NewReader([]byte(
+			`fields: [title, head_branch]
+labels:
+  'bug':
+    headBranches: ['^fix/']
+  'release':
+    include: ['\brelease\b']
+    headBranches: ['^release/']
+  'area/api':
+    include: ['\bapi\b']
+`))), nil, nil)
+	pr := &github.PullRequest{
+		Title: ptr("prepare release"),
+		Head:  &github.PullRequestBranch{Ref: ptr("fix/api-timeout")},
+		Base:  &github.PullRequestBranch{Ref: ptr("main")},
+	}
+	mockClient.On("GetPullRequest", mock.Anything, "owner", "repo", 1).Return(pr, nil, nil)
+	mockClient.On("AddLabelsToIssue", mock.Anything, "owner", "repo", 1, []string{"area/api", "bug"}).
+		Return([]*github.Label{{Name: ptr("area/api")}, {Name: ptr("bug")}}, nil, nil)
+
+	assert.NoError(t, l.Execute())
+	mockClient.AssertExpectations(t)
+
+	explanations, err := l.explain(pr)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.Explanation{
+		{
+			Label:   "area/api",
+			Matches: []model.Match{{Field: "head_branch", Pattern: `\bapi\b`, Text: "api", Start: 4, End: 7}},
+		},
+		{
+			Label:   "bug",
+			Matches: []model.Match{{Field: "head_branch", Pattern: "^fix/", Text: "fix/", Start: 0, End: 4}},
+		},
+		{
+			Label:      "release",
+			Matches:    []model.Match{{Field: "title", Pattern: `\brelease\b`, Text: "release", Start: 8, End: 15}},
+			Suppressed: `head branch "fix/api-timeout" does not match headBranches [^release/]`,
+		},
+	}, explanations)
+}
+
+func TestLabeler_Execute_sync_removes_stale_labels(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(mockRichClient)
+
+	l := &Labeler{
+		Owner:      ptr("owner"),
+		Repo:       ptr("repo"),
+		Event:      ptr("issues"),
+		ID:         ptr(1),
+		context:    &ctx,
+		client:     mockClient,
+		configPath: ".github/labeler.yml",
+	}
+	mockClient.On("DownloadContents", mock.Anything, "owner", "repo", ".github/labeler.yml", mock.Anything).
+		Return(io.NopCloser(bytes.NewReader([]byte(
+			`sync: true
+labels:
+  'bug':
+    include:
+      - '\bbug[s]?\b'
+  'enhancement':
+    include:
+      - '\bfeature\b'
+`))), nil, nil)
+	mockClient.On("GetIssue", mock.Anything, "owner", "repo", 1).
+		Return(&github.Issue{
+			Title:  ptr("feature: do the thing"),
+			Body:   ptr("b"),
+			Labels: []*github.Label{{Name: ptr("bug")}, {Name: ptr("triage")}},
+		}, nil, nil)
+	mockClient.On("RemoveLabelForIssue", mock.Anything, "owner", "repo", 1, "bug").Return(nil, nil)
+	mockClient.On("AddLabelsToIssue", mock.Anything, "owner", "repo", 1, []string{"enhancement"}).
+		Return([]*github.Label{{Name: ptr("enhancement")}}, nil, nil)
+
+	err := l.Execute()
+	assert.NoError(t, err)
+	mockClient.AssertNumberOfCalls(t, "RemoveLabelForIssue", 1)
+	mockClient.AssertNotCalled(t, "RemoveLabelForIssue", mock.Anything, "owner", "repo", 1, "triage")
+	mockClient.AssertExpectations(t)
+}
+
+func TestLabeler_Execute_sync_removes_stale_conventional_labels(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(mockRichClient)
+
+	l := &Labeler{
+		Owner:      ptr("owner"),
+		Repo:       ptr("repo"),
+		Event:      ptr("pull_request"),
+		ID:         ptr(1),
+		context:    &ctx,
+		client:     mockClient,
+		configPath: ".github/labeler.yml",
+	}
+	mockClient.On("DownloadContents", mock.Anything, "owner", "repo", ".github/labeler.yml", mock.Anything).
+		Return(io.NopCloser(bytes.NewReader([]byte(
+			`sync: true
+conventional:
+  types:
+    feat: enhancement
+    fix: bug
+labels:
+  'question':
+    include: ['\?']
+`))), nil, nil)
+	// the pull request was retitled from "feat: ..." to "fix: ..."
+	mockClient.On("GetPullRequest", mock.Anything, "owner", "repo", 1).
+		Return(&github.PullRequest{
+			Title:  ptr("fix: handle empty titles"),
+			Body:   ptr("b"),
+			Labels: []*github.Label{{Name: ptr("enhancement")}, {Name: ptr("triage")}},
+		}, nil, nil)
+	mockClient.On("RemoveLabelForIssue", mock.Anything, "owner", "repo", 1, "enhancement").Return(nil, nil)
+	mockClient.On("AddLabelsToIssue", mock.Anything, "owner", "repo", 1, []string{"bug"}).
+		Return([]*github.Label{{Name: ptr("bug")}}, nil, nil)
+
+	result, err := l.ExecuteWithResult(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"enhancement"}, result.Removed)
+	assert.Equal(t, []string{"bug"}, result.Added)
+	assert.Nil(t, result.Matches, "matches are only explained when reported")
+	mockClient.AssertNumberOfCalls(t, "RemoveLabelForIssue", 1)
+	mockClient.AssertExpectations(t)
+}
+
+func TestLabeler_Execute_without_sync_keeps_stale_labels(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(mockRichClient)
+
+	l := &Labeler{
+		Owner:      ptr("owner"),
+		Repo:       ptr("repo"),
+		Event:      ptr("issues"),
+		ID:         ptr(1),
+		context:    &ctx,
+		client:     mockClient,
+		configPath: ".github/labeler.yml",
+	}
+	mockClient.On("DownloadContents", mock.Anything, "owner", "repo", ".github/labeler.yml", mock.Anything).
+		Return(io.NopCloser(bytes.NewReader([]byte(
+			`labels:
+  'bug':
+    include:
+      - '\bbug[s]?\b'
+`))), nil, nil)
+	mockClient.On("GetIssue", mock.Anything, "owner", "repo", 1).
+		Return(&github.Issue{Title: ptr("feature"), Body: ptr("b"), Labels: []*github.Label{{Name: ptr("bug")}}}, nil, nil)
+
+	err := l.Execute()
+	assert.NoError(t, err)
+	mockClient.AssertNotCalled(t, "RemoveLabelForIssue", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockClient.AssertExpectations(t)
+}
+
+func TestLabeler_ExecuteWithResult(t *testing.T) {
+	mockClient := new(mockRichClient)
+	l := &Labeler{
+		Owner:      ptr("owner"),
+		Repo:       ptr("repo"),
+		Event:      ptr("pull_request"),
+		ID:         ptr(1),
+		Data:       ptr(`{"pull_request":{"number":1,"title":"feature: fix bug in docs","base":{"ref":"main"},"labels":[{"name":"bug"},{"name":"question"}]}}`),
+		client:     mockClient,
+		configPath: ".github/labeler.yml",
+	}
+	mockClient.On("DownloadContents", mock.Anything, "owner", "repo", ".github/labeler.yml", mock.Anything).
+		Return(io.NopCloser(bytes.NewReader([]byte(
+			`sync: true
+comments:
+  prs: 'Thanks!'
+labels:
+  'bug':
+    include: ['\bbug\b']
+  'docs':
+    include: ['\bdocs\b']
+    branches: ['release']
+  'enhancement':
+    include: ['\bfeature\b']
+  'question':
+    include: ['\?']
+`))), nil, nil)
+	mockClient.On("RemoveLabelForIssue", mock.Anything, "owner", "repo", 1, "question").Return(nil, nil)
+	mockClient.On("AddLabelsToIssue", mock.Anything, "owner", "repo", 1, []string{"enhancement"}).
+		Return([]*github.Label{{Name: ptr("enhancement")}}, nil, nil)
+	mockClient.On("CreateComment", mock.Anything, "owner", "repo", 1, mock.Anything).
+		Return(&github.IssueComment{ID: github.Int64(7)}, nil, nil)
+
+	result, err := l.ExecuteWithResult(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, &Result{
+		Event:     "pull_request",
+		Number:    1,
+		Evaluated: []string{"bug", "docs", "enhancement"},
+		Matches: map[string][]model.Match{
+			"bug":         {{Field: "title", Pattern: `\bbug\b`, Text: "bug", Start: 13, End: 16}},
+			"docs":        {{Field: "title", Pattern: `\bdocs\b`, Text: "docs", Start: 20, End: 24}},
+			"enhancement": {{Field: "title", Pattern: `\bfeature\b`, Text: "feature", Start: 0, End: 7}},
+		},
+		Added: []string{"enhancement"},
+		Skipped: []SkippedLabel{
+			{Name: "bug", Reason: "already applied"},
+			{Name: "docs", Reason: `target branch "main" does not match branches [release]`},
+		},
+		Removed: []string{"question"},
+		Comment: &github.IssueComment{ID: github.Int64(7)},
+	}, result)
+	mockClient.AssertExpectations(t)
+}
+
+func TestLabeler_ExecuteWithResult_label_errors(t *testing.T) {
+	mockClient := new(mockRichClient)
+	l := &Labeler{
+		Owner:      ptr("owner"),
+		Repo:       ptr("repo"),
+		Event:      ptr("issues"),
+		ID:         ptr(1),
+		Data:       ptr(`{"issue":{"number":1,"title":"a feature","labels":[{"name":"bug"}]}}`),
+		client:     mockClient,
+		configPath: ".github/labeler.yml",
+	}
+	mockClient.On("DownloadContents", mock.Anything, "owner", "repo", ".github/labeler.yml", mock.Anything).
+		Return(io.NopCloser(bytes.NewReader([]byte(
+			`sync: true
+comments:
+  issues: 'Thanks!'
+labels:
+  'bug':
+    include: ['\bbug\b']
+  'enhancement':
+    include: ['\bfeature\b']
+`))), nil, nil)
+	mockClient.On("RemoveLabelForIssue", mock.Anything, "owner", "repo", 1, "bug").Return(nil, errors.New("not found"))
+	mockClient.On("AddLabelsToIssue", mock.Anything, "owner", "repo", 1, []string{"enhancement"}).
+		Return([]*github.Label(nil), nil, errors.New("forbidden"))
+
+	result, err := l.ExecuteWithResult(context.Background())
+	assert.EqualError(t, err, "unable to remove label \"bug\": not found\nunable to add labels [enhancement]: forbidden")
+	assert.Equal(t, []string{"enhancement"}, result.Evaluated)
+	assert.Empty(t, result.Added)
+	assert.Empty(t, result.Removed)
+	mockClient.AssertNotCalled(t, "CreateComment", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockClient.AssertExpectations(t)
+}
+
+func TestLabeler_Execute_disabled_events(t *testing.T) {
+	tests := []struct {
+		name   string
+		event  string
+		config string
+	}{
+		{"full config prs disabled", "pull_request", "enable:\n  prs: false\nlabels:\n  'bug':\n    include: ['bug']\n"},
+		{"full config prs disabled for target", "pull_request_target", "enable:\n  prs: false\nlabels:\n  'bug':\n    include: ['bug']\n"},
+		{"full config issues disabled", "issues", "enable:\n  issues: false\nlabels:\n  'bug':\n    include: ['bug']\n"},
+		{"simple config prs disabled", "pull_request", "enable:\n  prs: false\nlabels:\n  'bug': ['bug']\n"},
+		{"simple config issues disabled", "issues", "enable:\n  issues: false\nlabels:\n  'bug': ['bug']\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			mockClient := new(mockRichClient)
+
+			l := &Labeler{
+				Owner:      ptr("owner"),
+				Repo:       ptr("repo"),
+				Event:      ptr(tt.event),
+				ID:         ptr(1),
+				context:    &ctx,
+				client:     mockClient,
+				configPath: ".github/labeler.yml",
+			}
+			mockClient.On("DownloadContents", mock.Anything, "owner", "repo", ".github/labeler.yml", mock.Anything).
+				Return(io.NopCloser(bytes.NewReader([]byte(tt.config))), nil, nil)
+
+			err := l.Execute()
+			assert.NoError(t, err)
+			mockClient.AssertNotCalled(t, "GetIssue", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			mockClient.AssertNotCalled(t, "GetPullRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLabeler_Explain(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(mockRichClient)
+
+	l := &Labeler{
+		Owner:      ptr("owner"),
+		Repo:       ptr("repo"),
+		Event:      ptr("pull_request"),
+		ID:         ptr(1),
+		context:    &ctx,
+		client:     mockClient,
+		configPath: ".github/labeler.yml",
+	}
+	mockClient.On("DownloadContents", mock.Anything, "owner", "repo", ".github/labeler.yml", mock.Anything).
+		Return(io.NopCloser(bytes.NewReader([]byte(
+			`labels:
+  'bug':
+    include:
+      - '\bbug[s]?\b'
+    branches:
+      - main
+  'deploy':
+    include:
+      - '\bJIRA-\d{1,}\b'
+    branches:
+      - production
+  'docs':
+    files:
+      include:
+        - 'docs/**'
+  'help wanted':
+    include:
+      - '\bhelp\b'
+    exclude:
+      - '\bWIP\b'
+`))), nil, nil)
+	mockClient.On("GetPullRequest", mock.Anything, "owner", "repo", 1).
+		Return(&github.PullRequest{Title: ptr("WIP: JIRA-12 help"), Body: ptr("fixes bugs"), Base: &github.PullRequestBranch{Ref: ptr("main")}}, nil, nil)
+	mockClient.On("ListPullRequestFiles", mock.Anything, "owner", "repo", 1).
+		Return([]*github.CommitFile{{Filename: ptr("docs/index.md")}}, nil, nil)
+
+	explanations, err := l.Explain()
+	assert.NoError(t, err)
+	assert.Equal(t, []model.Explanation{
+		{
+			Label:   "bug",
+			Matches: []model.Match{{Field: "body", Pattern: `\bbug[s]?\b`, Text: "bugs", Start: 6, End: 10}},
+		},
+		{
+			Label:      "deploy",
+			Matches:    []model.Match{{Field: "title", Pattern: `\bJIRA-\d{1,}\b`, Text: "JIRA-12", Start: 5, End: 12}},
+			Suppressed: `target branch "main" does not match branches [production]`,
+		},
+		{
+			Label:   "docs",
+			Matches: []model.Match{{Field: "files", Pattern: "docs/**", Text: "docs/index.md", Start: 0, End: 13}},
+		},
+		{
+			Label:      "help wanted",
+			Matches:    []model.Match{{Field: "title", Pattern: `\bhelp\b`, Text: "help", Start: 13, End: 17}},
+			Suppressed: "excluded by title[0:3] \"WIP\" matched `\\bWIP\\b`",
+		},
+	}, explanations)
+	mockClient.AssertNotCalled(t, "AddLabelsToIssue", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockClient.AssertExpectations(t)
+}
+
+func TestLabeler_Execute_dry_run(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(mockRichClient)
+	out := new(bytes.Buffer)
+
+	l := &Labeler{
+		Owner:      ptr("owner"),
+		Repo:       ptr("repo"),
+		Event:      ptr("issues"),
+		ID:         ptr(1),
+		context:    &ctx,
+		client:     mockClient,
+		configPath: ".github/labeler.yml",
+		dryRun:     true,
+		out:        out,
+	}
+	mockClient.On("DownloadContents", mock.Anything, "owner", "repo", ".github/labeler.yml", mock.Anything).
+		Return(io.NopCloser(bytes.NewReader([]byte(
+			`sync: true
+comments:
+  issues: Thanks!
+labels:
+  'bug':
+    include:
+      - '\bbug[s]?\b'
+  'question':
+    include:
+      - '\bquestion\b'
+`))), nil, nil)
+	mockClient.On("GetIssue", mock.Anything, "owner", "repo", 1).
+		Return(&github.Issue{Title: ptr("a bug"), Body: ptr("b"), Labels: []*github.Label{{Name: ptr("question")}}}, nil, nil)
+
+	err := l.Execute()
+	assert.NoError(t, err)
+	assert.Equal(t, `bug: applied
+  title[2:5] "bug" matched `+"`\\bbug[s]?\\b`"+`
+question: no match
+dry-run: would remove label "question" from #1
+dry-run: would add labels [bug] to #1
+dry-run: would comment on #1:
+Thanks!
+`, out.String())
+	mockClient.AssertNotCalled(t, "AddLabelsToIssue", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockClient.AssertNotCalled(t, "RemoveLabelForIssue", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockClient.AssertNotCalled(t, "CreateComment", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockClient.AssertExpectations(t)
+}
+
+func TestLabeler_Execute_invalid_patterns(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(mockRichClient)
+
+	l := &Labeler{
+		Owner:      ptr("owner"),
+		Repo:       ptr("repo"),
+		Event:      ptr("issues"),
+		ID:         ptr(1),
+		context:    &ctx,
+		client:     mockClient,
+		configPath: ".github/labeler.yml",
+	}
+	mockClient.On("DownloadContents", mock.Anything, "owner", "repo", ".github/labeler.yml", mock.Anything).
+		Return(io.NopCloser(bytes.NewReader([]byte(
+			`labels:
+  'bug':
+    include:
+      - '(bug'
+  'question':
+    include:
+      - '[question'
+`))), nil, nil)
+
+	err := l.Execute()
+	var problems model.ConfigErrors
+	if assert.ErrorAs(t, err, &problems) {
+		assert.Len(t, problems, 2)
+		assert.Equal(t, 4, problems[0].Line)
+		assert.Equal(t, 7, problems[1].Line)
+	}
+	mockClient.AssertNotCalled(t, "GetIssue", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockClient.AssertExpectations(t)
+}
+
 func TestLabeler_Execute_fail_to_parse_config(t *testing.T) {
 	ctx := context.Background()
 	mockClient := new(mockRichClient)
@@ -550,6 +1441,10 @@ func TestLabeler_Execute_fail_to_parse_config(t *testing.T) {
 	err := l.Execute()
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "could not parse \".github/labeler.yml\"")
+	var problems model.ConfigErrors
+	if assert.ErrorAs(t, err, &problems) {
+		assert.Equal(t, model.ConfigErrors{{File: ".github/labeler.yml", Line: 1, Column: 1, Message: "cannot unmarshal !!str `bananas` into a full config"}}, problems)
+	}
 
 	mockClient.AssertNumberOfCalls(t, "DownloadContents", 1)
 	mockClient.AssertExpectations(t)
@@ -0,0 +1,158 @@
+package labeler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v50/github"
+	log "github.com/sirupsen/logrus"
+)
+
+// LabelChange describes a repository label which must be created or updated to match its definition in the config
+type LabelChange struct {
+	// Name is the name of the label
+	Name string
+	// Color is the desired color, or empty if the color is unchanged (or left to GitHub's default for a new label)
+	Color string
+	// Description is the desired description, or empty if the description is unchanged
+	Description string
+	// Existing is the current repository label, or nil if the label is created
+	Existing *github.Label
+}
+
+// String formats the change as a line of a diff: + for a created label, ~ for an updated label
+func (c LabelChange) String() string {
+	if c.Existing == nil {
+		var sb strings.Builder
+		sb.WriteString("+ " + c.Name)
+		if c.Color != "" {
+			sb.WriteString(" color " + c.Color)
+		}
+		if c.Description != "" {
+			sb.WriteString(fmt.Sprintf(" description %q", c.Description))
+		}
+		return sb.String()
+	}
+
+	changes := make([]string, 0, 2)
+	if c.Color != "" {
+		changes = append(changes, fmt.Sprintf("color %s -> %s", c.Existing.GetColor(), c.Color))
+	}
+	if c.Description != "" {
+		changes = append(changes, fmt.Sprintf("description %q -> %q", c.Existing.GetDescription(), c.Description))
+	}
+	return fmt.Sprintf("~ %s: %s", c.Name, strings.Join(changes, ", "))
+}
+
+// PlanLabels compares the labels of the repository to the labels defined in the config, returning the changes
+// required for every configured label to exist with its configured color and description. Nothing is modified.
+func (l *Labeler) PlanLabels() ([]LabelChange, error) {
+	if err := l.checkRepository(); err != nil {
+		return nil, err
+	}
+
+	c, err := l.retrieveConfig()
+	if err != nil {
+		return nil, err
+	}
+	l.config = c
+
+	return l.planLabels(l.config.Rules().Names())
+}
+
+// ApplyLabelChanges creates or updates repository labels, as planned by PlanLabels
+func (l *Labeler) ApplyLabelChanges(changes []LabelChange) error {
+	var errs []error
+	for _, change := range changes {
+		label := &github.Label{Name: github.String(change.Name)}
+		if change.Color != "" {
+			label.Color = github.String(change.Color)
+		}
+		if change.Description != "" {
+			label.Description = github.String(change.Description)
+		}
+
+		ctx, cancel := context.WithTimeout(*l.context, 10*time.Second)
+		var err error
+		if change.Existing == nil {
+			_, _, err = l.client.CreateLabel(ctx, *l.Owner, *l.Repo, label)
+		} else {
+			_, _, err = l.client.EditLabel(ctx, *l.Owner, *l.Repo, change.Existing.GetName(), label)
+		}
+		cancel()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("unable to apply label change %q: %w", change, err))
+			continue
+		}
+		log.Debugf("Applied label change %q", change)
+	}
+	return errors.Join(errs...)
+}
+
+// planLabels determines the changes required for the named labels to match their definitions in the config
+func (l *Labeler) planLabels(names []string) ([]LabelChange, error) {
+	ctx, cancel := context.WithTimeout(*l.context, 30*time.Second)
+	defer cancel()
+	labels, _, err := l.client.ListLabels(ctx, *l.Owner, *l.Repo)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list labels: %w", err)
+	}
+
+	// label names are case-insensitive
+	existing := make(map[string]*github.Label, len(labels))
+	for _, label := range labels {
+		existing[strings.ToLower(label.GetName())] = label
+	}
+
+	names = append([]string(nil), names...)
+	sort.Strings(names)
+	rules := l.config.Rules()
+	changes := make([]LabelChange, 0)
+	for _, name := range names {
+		rule := rules.Rule(name)
+		if rule == nil {
+			continue
+		}
+		definition := rule.Label
+
+		current, ok := existing[strings.ToLower(name)]
+		if !ok {
+			changes = append(changes, LabelChange{Name: name, Color: definition.NormalizedColor(), Description: definition.Description})
+			continue
+		}
+
+		change := LabelChange{Name: current.GetName(), Existing: current}
+		if definition.Color != "" && definition.NormalizedColor() != strings.ToLower(current.GetColor()) {
+			change.Color = definition.NormalizedColor()
+		}
+		if definition.Description != "" && definition.Description != current.GetDescription() {
+			change.Description = definition.Description
+		}
+		if change.Color != "" || change.Description != "" {
+			changes = append(changes, change)
+		}
+	}
+	return changes, nil
+}
+
+// syncLabelDefinitions creates or updates the named labels before they're added to an issue or pull request, so
+// GitHub doesn't create them without a color or description
+func (l *Labeler) syncLabelDefinitions(names []string) error {
+	changes, err := l.planLabels(names)
+	if err != nil {
+		return err
+	}
+
+	if l.dryRun {
+		for _, change := range changes {
+			_, _ = fmt.Fprintf(l.writer(), "dry-run: would change label %s\n", change)
+		}
+		return nil
+	}
+
+	return l.ApplyLabelChanges(changes)
+}
@@ -0,0 +1,135 @@
+package labeler
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-github/v50/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const labelsConfig = `labels:
+  'bug':
+    include: ['\bbug[s]?\b']
+    color: '#D73A4A'
+    description: "Something isn't working"
+  'enhancement':
+    include: ['\bfeat\b']
+    color: a2eeef
+  'question':
+    include: ['\bquestion\b']
+    description: Further information is requested
+  'help wanted':
+    include: ['\bhelp\b']
+`
+
+func TestLabeler_PlanLabels(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(mockRichClient)
+	l := newTestLabeler(&ctx, mockClient, labelsConfig, "issues")
+
+	existing := []*github.Label{
+		{Name: ptr("Bug"), Color: ptr("ededed"), Description: ptr("")},
+		{Name: ptr("enhancement"), Color: ptr("A2EEEF"), Description: ptr("New feature or request")},
+		{Name: ptr("wontfix"), Color: ptr("ffffff")},
+	}
+	mockClient.On("ListLabels", mock.Anything, "owner", "repo").Return(existing, nil, nil)
+
+	changes, err := l.PlanLabels()
+	assert.NoError(t, err)
+	assert.Equal(t, []LabelChange{
+		{Name: "Bug", Color: "d73a4a", Description: "Something isn't working", Existing: existing[0]},
+		{Name: "help wanted"},
+		{Name: "question", Description: "Further information is requested"},
+	}, changes, "enhancement is unchanged, as colors are compared case-insensitively and its description isn't configured")
+}
+
+func TestLabeler_ApplyLabelChanges(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(mockRichClient)
+	l := &Labeler{Owner: ptr("owner"), Repo: ptr("repo"), context: &ctx, client: mockClient}
+
+	mockClient.On("EditLabel", mock.Anything, "owner", "repo", "Bug", &github.Label{Name: ptr("Bug"), Color: ptr("d73a4a")}).
+		Return(nil, nil, nil)
+	mockClient.On("CreateLabel", mock.Anything, "owner", "repo", &github.Label{Name: ptr("help wanted")}).
+		Return(nil, nil, errors.New("forbidden"))
+	mockClient.On("CreateLabel", mock.Anything, "owner", "repo", &github.Label{Name: ptr("question"), Description: ptr("Questions")}).
+		Return(nil, nil, nil)
+
+	err := l.ApplyLabelChanges([]LabelChange{
+		{Name: "Bug", Color: "d73a4a", Existing: &github.Label{Name: ptr("Bug"), Color: ptr("ededed")}},
+		{Name: "help wanted"},
+		{Name: "question", Description: "Questions"},
+	})
+	assert.EqualError(t, err, `unable to apply label change "+ help wanted": forbidden`)
+	mockClient.AssertExpectations(t)
+}
+
+func TestLabeler_Execute_sync_labels(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(mockRichClient)
+	l := newTestLabeler(&ctx, mockClient, labelsConfig, "issues")
+	l.syncLabels = true
+	l.Data = ptr(`{"issue":{"number":1,"title":"bugs and a question"}}`)
+
+	mockClient.On("ListLabels", mock.Anything, "owner", "repo").Return([]*github.Label{
+		{Name: ptr("bug"), Color: ptr("d73a4a"), Description: ptr("Something isn't working")},
+	}, nil, nil)
+	mockClient.On("CreateLabel", mock.Anything, "owner", "repo", &github.Label{Name: ptr("question"), Description: ptr("Further information is requested")}).
+		Return(nil, nil, nil)
+	mockClient.On("AddLabelsToIssue", mock.Anything, "owner", "repo", 1, mock.Anything).
+		Return([]*github.Label{{Name: ptr("bug")}, {Name: ptr("question")}}, nil, nil)
+
+	assert.NoError(t, l.Execute())
+	mockClient.AssertExpectations(t)
+	mockClient.AssertNotCalled(t, "EditLabel", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestLabeler_Execute_sync_labels_errors(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(mockRichClient)
+	l := newTestLabeler(&ctx, mockClient, labelsConfig, "issues")
+	l.syncLabels = true
+	l.Data = ptr(`{"issue":{"number":1,"title":"a question"}}`)
+
+	mockClient.On("ListLabels", mock.Anything, "owner", "repo").Return([]*github.Label{}, nil, nil)
+	mockClient.On("CreateLabel", mock.Anything, "owner", "repo", mock.Anything).Return(nil, nil, errors.New("forbidden"))
+	mockClient.On("AddLabelsToIssue", mock.Anything, "owner", "repo", 1, []string{"question"}).
+		Return([]*github.Label{{Name: ptr("question")}}, nil, nil)
+
+	result, err := l.ExecuteWithResult(ctx)
+	assert.EqualError(t, err, `unable to apply label change "+ question description \"Further information is requested\"": forbidden`)
+	assert.Equal(t, []string{"question"}, result.Added)
+	mockClient.AssertExpectations(t)
+}
+
+func TestLabelChange_String(t *testing.T) {
+	tests := []struct {
+		name   string
+		change LabelChange
+		want   string
+	}{
+		{
+			name:   "create",
+			change: LabelChange{Name: "bug", Color: "d73a4a", Description: "Something isn't working"},
+			want:   `+ bug color d73a4a description "Something isn't working"`,
+		},
+		{
+			name:   "create with defaults",
+			change: LabelChange{Name: "bug"},
+			want:   `+ bug`,
+		},
+		{
+			name:   "update",
+			change: LabelChange{Name: "bug", Color: "d73a4a", Description: "Broken", Existing: &github.Label{Name: ptr("bug"), Color: ptr("ededed")}},
+			want:   `~ bug: color ededed -> d73a4a, description "" -> "Broken"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.change.String())
+		})
+	}
+}
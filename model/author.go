@@ -0,0 +1,105 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// authorTypePrefix prefixes an author entry matching the account type, e.g. type:bot
+	authorTypePrefix = "type:"
+	// authorAssociationPrefix prefixes an author entry matching the author association, e.g. association:first_time_contributor
+	authorAssociationPrefix = "association:"
+)
+
+// authorTypes are the account types reported by GitHub
+var authorTypes = []string{"bot", "user", "organization", "mannequin"}
+
+// authorAssociations are the associations of an author with a repository reported by GitHub
+var authorAssociations = []string{
+	"collaborator", "contributor", "first_timer", "first_time_contributor", "mannequin", "member", "none", "owner",
+}
+
+type (
+	// AuthorRule holds the authors for whom a label is applied or suppressed. Each entry is a login (e.g.
+	// dependabot[bot]), an account type prefixed by type: (e.g. type:bot), or an author association prefixed by
+	// association: (e.g. association:FIRST_TIME_CONTRIBUTOR). Entries are compared case-insensitively.
+	AuthorRule struct {
+		Include []string `yaml:"include,omitempty,flow" json:"include,omitempty"`
+		Exclude []string `yaml:"exclude,omitempty,flow" json:"exclude,omitempty"`
+	}
+
+	// Author is the user who opened an issue or pull request
+	Author struct {
+		// Login is the author's login, e.g. dependabot[bot]
+		Login string
+		// Type is the author's account type, e.g. Bot or User
+		Type string
+		// Association is the author's association with the repository, e.g. FIRST_TIME_CONTRIBUTOR or MEMBER
+		Association string
+	}
+)
+
+// String formats the author as their login
+func (a Author) String() string {
+	return a.Login
+}
+
+// match returns the first entry matching the author, or an empty string if none match
+func (a Author) match(entries []string) string {
+	for _, entry := range entries {
+		value, want := a.Login, entry
+		switch {
+		case hasPrefixFold(entry, authorTypePrefix):
+			value, want = a.Type, entry[len(authorTypePrefix):]
+		case hasPrefixFold(entry, authorAssociationPrefix):
+			value, want = a.Association, entry[len(authorAssociationPrefix):]
+		}
+		if value != "" && strings.EqualFold(value, want) {
+			return entry
+		}
+	}
+	return ""
+}
+
+// compileAuthors checks each entry, appending a ConfigError for every unknown account type or author association
+func compileAuthors(entries []string, pointer string, problems *ConfigErrors) {
+	for i, entry := range entries {
+		if err := checkAuthor(entry); err != nil {
+			*problems = append(*problems, ConfigError{Path: fmt.Sprintf("%s/%d", pointer, i), Message: err.Error()})
+		}
+	}
+}
+
+// checkAuthor returns an error if an entry prefixed by type: or association: names an unknown value. Logins aren't checked.
+func checkAuthor(entry string) error {
+	var value string
+	var known []string
+	switch {
+	case hasPrefixFold(entry, authorTypePrefix):
+		value, known = entry[len(authorTypePrefix):], authorTypes
+	case hasPrefixFold(entry, authorAssociationPrefix):
+		value, known = entry[len(authorAssociationPrefix):], authorAssociations
+	default:
+		return nil
+	}
+	if !containsFold(known, value) {
+		return fmt.Errorf("invalid author %q: expected one of %s", entry, strings.Join(known, ", "))
+	}
+	return nil
+}
+
+// hasPrefixFold returns true if s begins with prefix, ignoring case
+func hasPrefixFold(s, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
+
+// containsFold returns true if values contains value, ignoring case
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
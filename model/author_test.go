@@ -0,0 +1,57 @@
+package model
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRule_AuthorAllowed(t *testing.T) {
+	rules, err := NewRuleSet(map[string]Label{
+		"dependencies": {Authors: &AuthorRule{Include: []string{"dependabot[bot]", "renovate[bot]"}}},
+		"good first contribution": {
+			Authors: &AuthorRule{Include: []string{"association:first_time_contributor", "association:FIRST_TIMER"}, Exclude: []string{"type:Bot"}},
+		},
+		"bug":      {Include: []string{`\bbug\b`}, Authors: &AuthorRule{Exclude: []string{"type:bot"}}},
+		"internal": {Include: []string{`\binternal\b`}, Authors: &AuthorRule{Include: []string{"association:MEMBER"}}},
+	})
+	assert.NoError(t, err)
+
+	dependabot := Author{Login: "Dependabot[bot]", Type: "Bot", Association: "NONE"}
+	newcomer := Author{Login: "octocat", Type: "User", Association: "FIRST_TIME_CONTRIBUTOR"}
+	member := Author{Login: "hubot", Type: "User", Association: "MEMBER"}
+
+	assert.Equal(t, []string{"dependencies"}, sortedLabelNames(rules.LabelsForAuthor(dependabot)))
+	assert.Equal(t, []string{"good first contribution"}, sortedLabelNames(rules.LabelsForAuthor(newcomer)))
+	assert.Empty(t, rules.LabelsForAuthor(member), "rules with include patterns aren't applied by author alone")
+
+	assert.False(t, rules.Rule("bug").AuthorAllowed(dependabot))
+	assert.True(t, rules.Rule("bug").AuthorExcluded(dependabot))
+	assert.True(t, rules.Rule("bug").AuthorAllowed(newcomer))
+	assert.True(t, rules.Rule("internal").AuthorAllowed(member))
+	assert.False(t, rules.Rule("internal").AuthorAllowed(newcomer))
+	assert.False(t, rules.Rule("internal").AuthorExcluded(newcomer))
+	assert.True(t, rules.Rule("missing").AuthorAllowed(newcomer))
+
+	assert.Equal(t, &Match{Field: "author", Pattern: "dependabot[bot]", Text: "Dependabot[bot]", Start: 0, End: 15},
+		rules.Rule("dependencies").MatchAuthor(dependabot))
+	assert.Nil(t, rules.Rule("dependencies").MatchAuthor(member))
+}
+
+func TestNewRuleSet_invalidAuthors(t *testing.T) {
+	_, err := NewRuleSet(map[string]Label{
+		"bug": {Include: []string{"bug"}, Authors: &AuthorRule{Include: []string{"type:robot", "octocat"}, Exclude: []string{"association:stranger"}}},
+	})
+	var problems ConfigErrors
+	if !errors.As(err, &problems) {
+		t.Fatalf("expected ConfigErrors, got %v", err)
+	}
+	assert.Equal(t, ConfigErrors{
+		{Path: "/labels/bug/authors/include/0", Message: `invalid author "type:robot": expected one of bot, user, organization, mannequin`},
+		{
+			Path:    "/labels/bug/authors/exclude/0",
+			Message: `invalid author "association:stranger": expected one of collaborator, contributor, first_timer, first_time_contributor, mannequin, member, none, owner`,
+		},
+	}, problems)
+}
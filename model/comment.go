@@ -0,0 +1,150 @@
+package model
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+type (
+	// CommentLabel is a label applied to an issue or pull request, as available to comment templates
+	CommentLabel struct {
+		// Name is the name of the label
+		Name string
+		// Matches are the patterns which matched the issue or pull request, causing the label to be applied
+		Matches []Match
+	}
+
+	// CommentData is the data available to comment templates
+	CommentData struct {
+		// Labels are the labels applied to the issue or pull request, in lexical order
+		Labels []CommentLabel
+		// Author is the login of the user who opened the issue or pull request
+		Author string
+		// Event is the type of event being labeled (e.g. issues or pull_request)
+		Event string
+		// Number is the number of the issue or pull request
+		Number int
+	}
+
+	// CommentTemplate is a comment parsed as a text/template. See CommentData for the available data.
+	CommentTemplate struct {
+		source string
+		tmpl   *template.Template
+	}
+
+	// commentTemplates are the parsed comments of a config
+	commentTemplates struct {
+		issues *CommentTemplate
+		prs    *CommentTemplate
+	}
+
+	// Commenter is implemented by configs which define comments to add when labels are applied
+	Commenter interface {
+		// IssueComment returns the comment to add to issues, or nil if there is none
+		IssueComment() *CommentTemplate
+		// PullRequestComment returns the comment to add to pull requests, or nil if there is none
+		PullRequestComment() *CommentTemplate
+		// CommentStrategy returns how comments are added when an issue or pull request is labeled more than once
+		CommentStrategy() CommentStrategy
+	}
+
+	// CommentStrategy determines how the labeler's comment is added when an issue or pull request is labeled more than once
+	CommentStrategy string
+)
+
+const (
+	// CommentStrategyCreate adds a new comment every time labels are applied
+	CommentStrategyCreate CommentStrategy = "create"
+	// CommentStrategyUpdate edits the labeler's previous comment in place, adding a comment only if there is none
+	CommentStrategyUpdate CommentStrategy = "update"
+	// CommentStrategyOnce adds a comment only if the labeler hasn't commented before
+	CommentStrategyOnce CommentStrategy = "once"
+)
+
+// commentFuncs are the functions available to comment templates, in addition to the text/template builtins
+var commentFuncs = template.FuncMap{
+	"join": strings.Join,
+}
+
+// sampleCommentData is representative of the data a comment is rendered with: a comment is only added once a label
+// is applied
+var sampleCommentData = CommentData{
+	Labels: []CommentLabel{
+		{Name: "bug", Matches: []Match{{Field: "title", Pattern: `\bbug\b`, Text: "bug", Start: 0, End: 3}}},
+	},
+	Author: "octocat",
+	Event:  "issues",
+	Number: 1,
+}
+
+// NewCommentTemplate parses the source of a comment as a text/template. Comments without actions are rendered as-is.
+// The template is trial executed against sample data, so references to unknown fields fail here rather than when the
+// comment is rendered.
+func NewCommentTemplate(source string) (*CommentTemplate, error) {
+	tmpl, err := template.New("comment").Funcs(commentFuncs).Option("missingkey=error").Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid comment template: %w", err)
+	}
+	if err := tmpl.Execute(io.Discard, sampleCommentData); err != nil {
+		return nil, fmt.Errorf("invalid comment template: %w", err)
+	}
+	return &CommentTemplate{source: source, tmpl: tmpl}, nil
+}
+
+// Valid returns true if the strategy is empty (the default) or one of the known strategies
+func (s CommentStrategy) Valid() bool {
+	switch s {
+	case "", CommentStrategyCreate, CommentStrategyUpdate, CommentStrategyOnce:
+		return true
+	}
+	return false
+}
+
+// String formats the label as its name
+func (c CommentLabel) String() string {
+	return c.Name
+}
+
+// Names returns the names of the applied labels
+func (d CommentData) Names() []string {
+	names := make([]string, 0, len(d.Labels))
+	for _, label := range d.Labels {
+		names = append(names, label.Name)
+	}
+	return names
+}
+
+// Render executes the template with the data. A template which failed to parse is rendered as its source text.
+func (c *CommentTemplate) Render(data CommentData) (string, error) {
+	if c.tmpl == nil {
+		return c.source, nil
+	}
+	var sb strings.Builder
+	if err := c.tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("unable to render comment template: %w", err)
+	}
+	return sb.String(), nil
+}
+
+// String returns the source of the template
+func (c *CommentTemplate) String() string {
+	return c.source
+}
+
+// compileComment parses a comment template, appending a ConfigError referencing pointer if it fails to parse.
+// An empty source has no template.
+func compileComment(source *string, pointer string, problems *ConfigErrors) *CommentTemplate {
+	if source == nil || *source == "" {
+		return nil
+	}
+	c, err := NewCommentTemplate(*source)
+	if err != nil {
+		if problems != nil {
+			*problems = append(*problems, ConfigError{Path: pointer, Message: err.Error()})
+		}
+		return &CommentTemplate{source: *source}
+	}
+	return c
+}
@@ -0,0 +1,136 @@
+package model
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommentTemplate_Render(t *testing.T) {
+	data := CommentData{
+		Labels: []CommentLabel{
+			{Name: "bug", Matches: []Match{{Field: "title", Pattern: `\bcrash(es)?\b`, Text: "crash", Start: 4, End: 9}}},
+			{Name: "question"},
+		},
+		Author: "octocat",
+		Event:  "issues",
+		Number: 42,
+	}
+
+	tests := []struct {
+		name    string
+		source  string
+		want    string
+		wantErr string
+	}{
+		{
+			name:   "static text",
+			source: "Thanks for opening this issue!",
+			want:   "Thanks for opening this issue!",
+		},
+		{
+			name:   "author, event and number",
+			source: "Thanks @{{ .Author }} for {{ .Event }} #{{ .Number }}",
+			want:   "Thanks @octocat for issues #42",
+		},
+		{
+			name:   "labels",
+			source: "Applied {{ range $i, $l := .Labels }}{{ if $i }}, {{ end }}`{{ $l }}`{{ end }} ({{ join .Names \", \" }})",
+			want:   "Applied `bug`, `question` (bug, question)",
+		},
+		{
+			name:   "matches",
+			source: "{{ range .Labels }}{{ with .Matches }}Added `{{ $.Event }}` because your {{ (index . 0).Field }} mentions '{{ (index . 0).Text }}'{{ end }}{{ end }}",
+			want:   "Added `issues` because your title mentions 'crash'",
+		},
+		{
+			name:    "execution error",
+			source:  "{{ range .Labels }}{{ (index .Matches 0).Text }}{{ end }}",
+			wantErr: "unable to render comment template",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewCommentTemplate(tt.source)
+			assert.NoError(t, err)
+			got, err := c.Render(data)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNewCommentTemplate_invalid(t *testing.T) {
+	_, err := NewCommentTemplate("Thanks {{ .Author }")
+	assert.ErrorContains(t, err, "invalid comment template")
+
+	_, err = NewCommentTemplate("hi {{.Authr}}")
+	assert.ErrorContains(t, err, "can't evaluate field Authr")
+
+	// a comment is only added once a label is applied
+	_, err = NewCommentTemplate("Added {{ (index .Labels 0).Name }} for '{{ (index (index .Labels 0).Matches 0).Text }}'")
+	assert.NoError(t, err)
+}
+
+func TestConfig_commentTemplates(t *testing.T) {
+	f := &FullConfig{}
+	err := f.FromBytes([]byte(`comments:
+  issues: 'Thanks {{ .Author }}'
+  prs: 'Thanks {{ .Author }'
+labels:
+  'bug':
+    include: ['bug']
+`))
+	var problems ConfigErrors
+	if !errors.As(err, &problems) {
+		t.Fatalf("expected ConfigErrors, got %v", err)
+	}
+	assert.Len(t, problems, 1)
+	assert.Equal(t, "/comments/prs", problems[0].Path)
+	assert.Equal(t, 3, problems[0].Line)
+
+	f = &FullConfig{}
+	err = f.FromBytes([]byte(`comments:
+  issues: 'hi {{.Authr}}'
+labels:
+  'bug':
+    include: ['bug']
+`))
+	if !errors.As(err, &problems) {
+		t.Fatalf("expected ConfigErrors, got %v", err)
+	}
+	assert.Len(t, problems, 1)
+	assert.Equal(t, "/comments/issues", problems[0].Path)
+	assert.Contains(t, problems[0].Message, "Authr")
+
+	s := &SimpleConfig{}
+	err = s.FromBytes([]byte(`comment: 'Thanks {{ .Author'
+labels:
+  'bug':
+    - 'bug'
+`))
+	if !errors.As(err, &problems) {
+		t.Fatalf("expected ConfigErrors, got %v", err)
+	}
+	assert.Len(t, problems, 1)
+	assert.Equal(t, "/comment", problems[0].Path)
+	assert.Equal(t, 1, problems[0].Line)
+
+	// a config constructed in code renders an invalid template as plain text
+	invalid := "Thanks {{ .Author }"
+	f = &FullConfig{Comments: Comments{Issues: &invalid}.Ptr()}
+	got, err := f.IssueComment().Render(CommentData{Author: "octocat"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Thanks {{ .Author }", got)
+	assert.Nil(t, f.PullRequestComment())
+
+	s = &SimpleConfig{Comment: "Thanks {{ .Author }}"}
+	got, err = s.PullRequestComment().Render(CommentData{Author: "octocat"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Thanks octocat", got)
+}
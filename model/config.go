@@ -1,5 +1,11 @@
 package model
 
+import (
+	"errors"
+
+	"gopkg.in/yaml.v3"
+)
+
 // Config is the interface used by simple and full config objects
 type Config interface {
 	// FromBytes is used to parse bytes into the Config instance
@@ -7,9 +13,58 @@ type Config interface {
 
 	// LabelsFor allows config implementations to determine the labels to be applied to the input strings
 	LabelsFor(text ...string) map[string]Label
+
+	// Rules returns the compiled rules of the config, which are built once and reused across evaluations
+	Rules() *RuleSet
 }
 
 type FieldOverrides interface {
 	// IncludedFields returns the fields that are used for labeling, if not defined, it returns an empty slice
 	IncludedFields() []string
 }
+
+// Kinds of config, declared by a config's kind key or else detected from its structure
+const (
+	// KindFull is the kind of FullConfig
+	KindFull = "full"
+	// KindSimple is the kind of SimpleConfig
+	KindSimple = "simple"
+)
+
+// ParseConfig parses config bytes as either schema: the kind declared by the config's kind key, or else the kind
+// detected from its structure. Every problem found, from YAML syntax and type errors to invalid patterns, is reported
+// together as ConfigErrors, ordered by line and column.
+func ParseConfig(b []byte) (Config, error) {
+	return parseConfig(b, false)
+}
+
+// ParseConfigFile parses one of the configs of a config directory like ParseConfig, except that a full config needn't
+// define labels of its own: the configs are merged (see MergeConfigFiles), and only the merged config requires labels.
+func ParseConfigFile(b []byte) (Config, error) {
+	return parseConfig(b, true)
+}
+
+// parseConfig parses config bytes as either schema; see ParseConfig and ParseConfigFile
+func parseConfig(b []byte, partial bool) (Config, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, ConfigErrors{yamlSyntaxError(err)}
+	}
+	if len(doc.Content) == 0 {
+		return nil, ConfigErrors{{Line: 1, Column: 1, Message: "config is empty"}}
+	}
+
+	// an unknown kind is reported by FromBytes, along with any other problems
+	var c Config = &FullConfig{partial: partial}
+	if configKind(doc.Content[0]) == KindSimple {
+		c = &SimpleConfig{}
+	}
+	if err := c.FromBytes(b); err != nil {
+		var problems ConfigErrors
+		if errors.As(err, &problems) {
+			sortConfigErrors(problems)
+		}
+		return nil, err
+	}
+	return c, nil
+}
@@ -0,0 +1,68 @@
+package model
+
+import (
+	"fmt"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFile is one of the configs of a config directory, e.g. .github/labeler.d/frontend.yml
+type ConfigFile struct {
+	// Name identifies the config in errors, e.g. its path
+	Name string
+	// Content is the YAML of the config
+	Content []byte
+}
+
+// MergeConfigFiles merges the configs of a config directory in the order given, which is expected to be lexical. The
+// labels of every config are combined, and a label may only be defined by more than one config if each defines it
+// identically; every conflicting definition is reported as ConfigErrors, located within the config which redefines the
+// label. Other values are deep merged, with the values of later configs taking precedence.
+func MergeConfigFiles(files ...ConfigFile) ([]byte, error) {
+	merged := map[string]interface{}{}
+	labels := map[string]interface{}{}
+	definedBy := map[string]string{}
+	var problems ConfigErrors
+	for _, file := range files {
+		var content interface{}
+		if err := yaml.Unmarshal(file.Content, &content); err != nil {
+			return nil, fmt.Errorf("could not parse %q: %w", file.Name, err)
+		}
+		doc, ok := mappingOf(content)
+		if !ok {
+			continue
+		}
+
+		if fileLabels, ok := mappingOf(doc["labels"]); ok {
+			var conflicts ConfigErrors
+			for name, rule := range fileLabels {
+				if previous, defined := definedBy[name]; defined && !reflect.DeepEqual(labels[name], rule) {
+					conflicts = append(conflicts, ConfigError{
+						Path:    "/labels/" + escapePointer(name),
+						Message: fmt.Sprintf("label %q is defined differently by %q and %q", name, previous, file.Name),
+					})
+					continue
+				}
+				labels[name] = rule
+				definedBy[name] = file.Name
+			}
+			located := locateConfigErrors(file.Content, conflicts)
+			sortConfigErrors(located)
+			for _, conflict := range located {
+				conflict.File = file.Name
+				problems = append(problems, conflict)
+			}
+		}
+		delete(doc, "labels")
+		merged = mergeValues(merged, doc).(map[string]interface{})
+	}
+
+	if len(problems) > 0 {
+		return nil, problems
+	}
+	if len(definedBy) > 0 {
+		merged["labels"] = labels
+	}
+	return yaml.Marshal(merged)
+}
@@ -0,0 +1,69 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeConfigFiles(t *testing.T) {
+	b, err := MergeConfigFiles(
+		ConfigFile{Name: "00-settings.yml", Content: []byte(`sync: true
+comments:
+  issues: Thank you!
+labels:
+  'bug':
+    include: ['\bbug\b']
+`)},
+		ConfigFile{Name: "backend.yml", Content: []byte(`comments:
+  prs: Thanks for the PR!
+labels:
+  'api':
+    files:
+      include: ['api/**']
+  'bug':
+    include: ['\bbug\b']
+`)},
+		ConfigFile{Name: "frontend.yml", Content: []byte(`sync: false
+labels:
+  'ui':
+    files:
+      include: ['web/**']
+`)},
+	)
+	assert.NoError(t, err)
+
+	f := &FullConfig{}
+	assert.NoError(t, f.FromBytes(b))
+	assert.False(t, f.Sync, "later configs take precedence")
+	assert.Equal(t, "Thank you!", *f.Comments.Issues)
+	assert.Equal(t, "Thanks for the PR!", *f.Comments.PullRequests)
+	assert.Equal(t, map[string]Label{
+		"bug": {Include: []string{`\bbug\b`}},
+		"api": {Files: &FileRule{Include: []string{"api/**"}}},
+		"ui":  {Files: &FileRule{Include: []string{"web/**"}}},
+	}, f.Labels)
+}
+
+func TestMergeConfigFiles_conflict(t *testing.T) {
+	_, err := MergeConfigFiles(
+		ConfigFile{Name: "backend.yml", Content: []byte("labels:\n  'bug':\n    include: ['\\bbug\\b']\n")},
+		ConfigFile{Name: "frontend.yml", Content: []byte("labels:\n  'bug':\n    include: ['\\bcrash\\b']\n")},
+	)
+	assert.Equal(t, ConfigErrors{{
+		File:    "frontend.yml",
+		Path:    "/labels/bug",
+		Line:    2,
+		Column:  3,
+		Message: `label "bug" is defined differently by "backend.yml" and "frontend.yml"`,
+	}}, err)
+
+	_, err = MergeConfigFiles(
+		ConfigFile{Name: "a.yml", Content: []byte("labels:\n  'bug':\n    include: [yes]\n")},
+		ConfigFile{Name: "b.yml", Content: []byte("labels:\n  'bug':\n    include: ['true']\n")},
+	)
+	assert.ErrorContains(t, err, `label "bug" is defined differently by "a.yml" and "b.yml"`, "yes isn't a boolean")
+
+	_, err = MergeConfigFiles(ConfigFile{Name: "broken.yml", Content: []byte("labels: [")})
+	assert.ErrorContains(t, err, `could not parse "broken.yml"`)
+}
@@ -0,0 +1,106 @@
+package model
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		want     Config
+		problems []ConfigError
+	}{
+		{
+			name:  "detected full config",
+			input: string(helperTestData(t, "full_config.yaml")),
+			want:  &FullConfig{},
+		},
+		{
+			name:  "detected simple config",
+			input: string(helperTestData(t, "simple_config_labels.yaml")),
+			want:  &SimpleConfig{},
+		},
+		{
+			name:  "declared simple config",
+			input: "kind: simple\nenable:\n  issues: false\nlabels: {}\n",
+			want:  &SimpleConfig{},
+		},
+		{
+			name:  "declared full config",
+			input: "kind: full\nlabels:\n  'bug':\n    include: ['bug']\n",
+			want:  &FullConfig{},
+		},
+		{
+			name: "every problem is reported",
+			input: `labels:
+  'bug':
+    include: 3
+    exclude: ['(']
+  'docs': [docs]
+`,
+			problems: []ConfigError{
+				{Line: 3, Column: 5, Message: "cannot unmarshal !!int `3` into a list of strings"},
+				{Path: "/labels/bug/exclude/0", Line: 4, Column: 15},
+				{Line: 5, Column: 3, Message: "cannot unmarshal !!seq into a label rule"},
+			},
+		},
+		{
+			name:  "declared kind doesn't match",
+			input: "kind: simple\nlabels:\n  'bug':\n    include: ['bug']\n",
+			problems: []ConfigError{
+				{Line: 4, Column: 5, Message: "cannot unmarshal !!map into a list of strings"},
+			},
+		},
+		{
+			name:  "unknown kind",
+			input: "kind: fancy\nlabels:\n  'bug': ['(']\n",
+			problems: []ConfigError{
+				{Path: "/kind", Line: 1, Column: 1, Message: `invalid kind "fancy": expected one of full, simple`},
+				{Path: "/labels/bug/0", Line: 3, Column: 11},
+			},
+		},
+		{
+			name:     "missing labels",
+			input:    "enable:\n  issues: true\n",
+			problems: []ConfigError{{Path: "/labels", Line: 1, Column: 1, Message: "full config requires labels to be defined"}},
+		},
+		{
+			name:     "yaml syntax error",
+			input:    "labels:\n  bug: [\n",
+			problems: []ConfigError{{Line: 2, Column: 1}},
+		},
+		{
+			name:     "empty config",
+			input:    "",
+			problems: []ConfigError{{Line: 1, Column: 1, Message: "config is empty"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := ParseConfig([]byte(tt.input))
+			if len(tt.problems) == 0 {
+				assert.NoError(t, err)
+				assert.IsType(t, tt.want, c)
+				return
+			}
+
+			var problems ConfigErrors
+			if !errors.As(err, &problems) {
+				t.Fatalf("expected ConfigErrors, got %v", err)
+			}
+			assert.Len(t, problems, len(tt.problems))
+			for i, want := range tt.problems {
+				assert.Equal(t, want.Path, problems[i].Path)
+				assert.Equal(t, want.Line, problems[i].Line)
+				assert.Equal(t, want.Column, problems[i].Column)
+				if want.Message != "" {
+					assert.Equal(t, want.Message, problems[i].Message)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package model
+
+import (
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// conventionalTitle matches the header of a Conventional Commits message: type(scope)!: subject
+var conventionalTitle = regexp.MustCompile(`^(\w[\w-]*)(?:\(([^()]+)\))?(!)?: +\S`)
+
+type (
+	// Conventional maps the parts of a Conventional Commits title, type(scope)!: subject, to labels
+	Conventional struct {
+		// Types are keyed by the commit type (e.g. feat), and valued by the label to apply
+		Types map[string]string `yaml:"types,omitempty" json:"types,omitempty"`
+		// Scopes are keyed by the scope (e.g. api), and valued by the label to apply. Comma separated scopes are each applied.
+		Scopes map[string]string `yaml:"scopes,omitempty" json:"scopes,omitempty"`
+		// Breaking is the label to apply to breaking changes, identified by a '!' preceding the colon
+		Breaking string `yaml:"breaking,omitempty" json:"breaking,omitempty"`
+	}
+
+	// TitleMatcher is implemented by configs which derive labels from the structure of a pull request title
+	TitleMatcher interface {
+		// MatchTitle returns the matches of each label derived from the title. ok is false if the title could not be parsed.
+		MatchTitle(title string) (matches map[string][]Match, ok bool)
+	}
+)
+
+// Match parses title as a Conventional Commits title, returning the matches of each mapped label. Types and scopes are
+// compared case-insensitively. ok is false if the title is not a Conventional Commits title.
+func (c *Conventional) Match(title string) (matches map[string][]Match, ok bool) {
+	loc := conventionalTitle.FindStringSubmatchIndex(title)
+	if loc == nil {
+		return nil, false
+	}
+
+	matches = make(map[string][]Match)
+	add := func(label, pattern string, start, end int) {
+		if label == "" {
+			return
+		}
+		matches[label] = append(matches[label], Match{Field: "title", Pattern: pattern, Text: title[start:end], Start: start, End: end})
+	}
+
+	commitType := title[loc[2]:loc[3]]
+	add(lookupFold(c.Types, commitType), "conventional type "+commitType, loc[2], loc[3])
+
+	if loc[4] >= 0 {
+		start := loc[4]
+		for _, scope := range strings.Split(title[loc[4]:loc[5]], ",") {
+			trimmed := strings.TrimSpace(scope)
+			if trimmed != "" {
+				offset := start + strings.Index(scope, trimmed)
+				add(lookupFold(c.Scopes, trimmed), "conventional scope "+trimmed, offset, offset+len(trimmed))
+			}
+			start += len(scope) + 1
+		}
+	}
+
+	if loc[6] >= 0 {
+		add(c.Breaking, "conventional breaking change", loc[6], loc[7])
+	}
+	return matches, true
+}
+
+// Labels returns the names of every label which may be derived from a title: those of each type and scope, and the
+// breaking change label
+func (c *Conventional) Labels() []string {
+	if c == nil {
+		return nil
+	}
+	var names []string
+	for _, values := range []map[string]string{c.Types, c.Scopes, {"": c.Breaking}} {
+		for _, name := range values {
+			if name != "" && !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lookupFold returns the value of key, preferring an exact match over a case-insensitive match
+func lookupFold(values map[string]string, key string) string {
+	if value, ok := values[key]; ok {
+		return value
+	}
+	for k, value := range values {
+		if strings.EqualFold(k, key) {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,73 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConventional_Match(t *testing.T) {
+	c := &Conventional{
+		Types:    map[string]string{"feat": "enhancement", "fix": "bug"},
+		Scopes:   map[string]string{"api": "area/api", "CLI": "area/cli"},
+		Breaking: "breaking",
+	}
+	tests := []struct {
+		name   string
+		title  string
+		want   map[string][]Match
+		wantOk bool
+	}{
+		{
+			name:   "type",
+			title:  "feat: add a thing",
+			want:   map[string][]Match{"enhancement": {{Field: "title", Pattern: "conventional type feat", Text: "feat", Start: 0, End: 4}}},
+			wantOk: true,
+		},
+		{
+			name:  "type, scopes and breaking change",
+			title: "Fix(api, cli)!: remove a thing",
+			want: map[string][]Match{
+				"bug":      {{Field: "title", Pattern: "conventional type Fix", Text: "Fix", Start: 0, End: 3}},
+				"area/api": {{Field: "title", Pattern: "conventional scope api", Text: "api", Start: 4, End: 7}},
+				"area/cli": {{Field: "title", Pattern: "conventional scope cli", Text: "cli", Start: 9, End: 12}},
+				"breaking": {{Field: "title", Pattern: "conventional breaking change", Text: "!", Start: 13, End: 14}},
+			},
+			wantOk: true,
+		},
+		{
+			name:   "unmapped type",
+			title:  "chore(deps): bump a thing",
+			want:   map[string][]Match{},
+			wantOk: true,
+		},
+		{
+			name:  "not conventional",
+			title: "Add a thing",
+		},
+		{
+			name:  "missing subject",
+			title: "feat:",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := c.Match(tt.title)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestConventional_Labels(t *testing.T) {
+	c := &Conventional{
+		Types:    map[string]string{"feat": "enhancement", "feature": "enhancement", "fix": "bug"},
+		Scopes:   map[string]string{"api": "area/api", "docs": ""},
+		Breaking: "breaking",
+	}
+	assert.Equal(t, []string{"area/api", "breaking", "bug", "enhancement"}, c.Labels())
+	assert.Nil(t, (*Conventional)(nil).Labels())
+
+	f := &FullConfig{Labels: map[string]Label{"bug": {Include: []string{"bug"}}, "docs": {}}, Conventional: c}
+	assert.ElementsMatch(t, []string{"area/api", "breaking", "bug", "docs", "enhancement"}, f.ManagedLabels())
+}
@@ -0,0 +1,109 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type (
+	// Field is a named value of an issue or pull request which is evaluated for labeling
+	Field struct {
+		Name string
+		Text string
+	}
+
+	// Match describes a pattern which matched a field of an issue or pull request
+	Match struct {
+		// Field is the name of the field containing the match (e.g. title, body, files)
+		Field string
+		// Pattern is the include pattern which matched
+		Pattern string
+		// Text is the matched substring
+		Text string
+		// Start is the byte offset of the match within the field
+		Start int
+		// End is the byte offset following the match within the field
+		End int
+	}
+
+	// Explanation describes how a single label was evaluated
+	Explanation struct {
+		Label   string
+		Matches []Match
+		// Suppressed is the reason a matching label will not be applied, if any
+		Suppressed string
+	}
+
+	// Explainer is implemented by configs which can describe why labels were or weren't selected
+	Explainer interface {
+		// Explain evaluates every label in the config against the named fields
+		Explain(fields ...Field) []Explanation
+	}
+)
+
+// Applied returns true if the label matched and was not suppressed
+func (e Explanation) Applied() bool {
+	return len(e.Matches) > 0 && e.Suppressed == ""
+}
+
+// String formats the explanation as a human-readable, multi-line summary
+func (e Explanation) String() string {
+	var sb strings.Builder
+	switch {
+	case e.Suppressed != "":
+		sb.WriteString(fmt.Sprintf("%s: suppressed, %s", e.Label, e.Suppressed))
+	case len(e.Matches) > 0:
+		sb.WriteString(fmt.Sprintf("%s: applied", e.Label))
+	default:
+		sb.WriteString(fmt.Sprintf("%s: no match", e.Label))
+	}
+	for _, m := range e.Matches {
+		sb.WriteString("\n  ")
+		sb.WriteString(m.String())
+	}
+	return sb.String()
+}
+
+// String formats the match as field[start:end] "text" matched `pattern`
+func (m Match) String() string {
+	return fmt.Sprintf("%s[%d:%d] %q matched `%s`", m.Field, m.Start, m.End, m.Text, m.Pattern)
+}
+
+// searchText joins fields for evaluation, in the same way as LabelsFor, while retaining the boundaries of each field
+type searchText struct {
+	text   string
+	fields []Field
+	starts []int
+}
+
+func newSearchText(fields []Field) searchText {
+	s := searchText{fields: fields, starts: make([]int, len(fields))}
+	parts := make([]string, len(fields))
+	offset := 0
+	for i, f := range fields {
+		s.starts[i] = offset
+		parts[i] = f.Text
+		offset += len(f.Text) + 1
+	}
+	s.text = strings.Join(parts, " ")
+	return s
+}
+
+// find returns the first match of re within the joined text, relative to the field in which the match starts
+func (s searchText) find(pattern string, re *regexp.Regexp) (Match, bool) {
+	loc := re.FindStringIndex(s.text)
+	if loc == nil {
+		return Match{}, false
+	}
+	m := Match{Pattern: pattern, Text: s.text[loc[0]:loc[1]], Start: loc[0], End: loc[1]}
+	for i := len(s.fields) - 1; i >= 0; i-- {
+		if loc[0] >= s.starts[i] {
+			m.Field = s.fields[i].Name
+			m.Start = loc[0] - s.starts[i]
+			m.End = min(loc[1]-s.starts[i], len(s.fields[i].Text))
+			break
+		}
+	}
+	return m, true
+}
@@ -0,0 +1,97 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFullConfig_Explain(t *testing.T) {
+	config := FullConfig{
+		Labels: map[string]Label{
+			"bug": {
+				Include: []string{`\bbug[s]?\b`},
+			},
+			"help wanted": {
+				Include: []string{`\bhelp( me)?\b`},
+				Exclude: []string{`\[test(ing)?\]`},
+			},
+			"enhancement": {
+				Include: []string{`\bfeat\b`},
+			},
+		},
+	}
+
+	got := config.Explain(
+		Field{Name: "title", Text: "[testing] help"},
+		Field{Name: "body", Text: "there are bugs"},
+	)
+
+	assert.Equal(t, []Explanation{
+		{
+			Label:   "bug",
+			Matches: []Match{{Field: "body", Pattern: `\bbug[s]?\b`, Text: "bugs", Start: 10, End: 14}},
+		},
+		{
+			Label: "enhancement",
+		},
+		{
+			Label:      "help wanted",
+			Matches:    []Match{{Field: "title", Pattern: `\bhelp( me)?\b`, Text: "help", Start: 10, End: 14}},
+			Suppressed: "excluded by title[0:9] \"[testing]\" matched `\\[test(ing)?\\]`",
+		},
+	}, got)
+
+	assert.True(t, got[0].Applied())
+	assert.False(t, got[1].Applied())
+	assert.False(t, got[2].Applied())
+}
+
+func TestSimpleConfig_Explain(t *testing.T) {
+	config := SimpleConfig{
+		Labels: map[string][]string{
+			"duplicate": {`\bduplicate\b`, `\bdupe\b`},
+			"question":  {`\bquestion\b`},
+		},
+	}
+
+	got := config.Explain(Field{Name: "title", Text: "a dupe"}, Field{Name: "body", Text: "duplicate"})
+
+	assert.Equal(t, []Explanation{
+		{
+			Label: "duplicate",
+			Matches: []Match{
+				{Field: "body", Pattern: `\bduplicate\b`, Text: "duplicate", Start: 0, End: 9},
+				{Field: "title", Pattern: `\bdupe\b`, Text: "dupe", Start: 2, End: 6},
+			},
+		},
+		{
+			Label: "question",
+		},
+	}, got)
+}
+
+func TestExplanation_String(t *testing.T) {
+	tests := []struct {
+		name        string
+		explanation Explanation
+		expected    string
+	}{
+		{"no match", Explanation{Label: "bug"}, "bug: no match"},
+		{
+			"applied",
+			Explanation{Label: "bug", Matches: []Match{{Field: "title", Pattern: `bug`, Text: "bug", Start: 2, End: 5}}},
+			"bug: applied\n  title[2:5] \"bug\" matched `bug`",
+		},
+		{
+			"suppressed",
+			Explanation{Label: "bug", Matches: []Match{{Field: "title", Pattern: `bug`, Text: "bug", Start: 0, End: 3}}, Suppressed: "excluded"},
+			"bug: suppressed, excluded\n  title[0:3] \"bug\" matched `bug`",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.explanation.String())
+		})
+	}
+}
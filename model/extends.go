@@ -0,0 +1,121 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultConfigPath is the path of a repository's config, relative to the repository root
+const DefaultConfigPath = ".github/labeler.yml"
+
+// ConfigReference identifies a config within a repository, as written in the extends key: owner/repo/path@ref. The path
+// defaults to DefaultConfigPath, and an empty ref refers to the default branch.
+type ConfigReference struct {
+	Owner string
+	Repo  string
+	Path  string
+	Ref   string
+}
+
+// ParseConfigReference parses owner/repo/path@ref, where both the path and @ref are optional
+func ParseConfigReference(value string) (ConfigReference, error) {
+	var reference ConfigReference
+	location, ref, hasRef := strings.Cut(strings.TrimSpace(value), "@")
+	if hasRef && ref == "" {
+		return reference, fmt.Errorf("invalid extends %q: expected a ref following @", value)
+	}
+	parts := strings.SplitN(location, "/", 3)
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return reference, fmt.Errorf("invalid extends %q: expected owner/repo/path@ref", value)
+	}
+
+	reference = ConfigReference{Owner: parts[0], Repo: parts[1], Path: DefaultConfigPath, Ref: ref}
+	if len(parts) == 3 {
+		reference.Path = strings.Trim(parts[2], "/")
+		if reference.Path == "" {
+			return reference, fmt.Errorf("invalid extends %q: expected owner/repo/path@ref", value)
+		}
+	}
+	return reference, nil
+}
+
+// String formats the reference as owner/repo/path, followed by @ref if there is a ref
+func (r ConfigReference) String() string {
+	s := r.Owner + "/" + r.Repo + "/" + r.Path
+	if r.Ref != "" {
+		s += "@" + r.Ref
+	}
+	return s
+}
+
+// ExtendsOf returns the value of the extends key of config bytes, or an empty string if the config doesn't extend
+// another config. Malformed configs are reported once the config is parsed, so they're treated as not extending another.
+func ExtendsOf(b []byte) string {
+	var doc struct {
+		Extends string `yaml:"extends"`
+	}
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return ""
+	}
+	return doc.Extends
+}
+
+// MergeConfigs deep merges the config bytes of override onto those of base. Mappings are merged key by key, with the
+// values of override taking precedence; any other value of override (including a sequence) replaces the value of base.
+// A null value in override deletes the key from base, e.g. 'bug': ~ under labels removes an inherited label. The
+// extends key of override is dropped, as base is expected to have been resolved already.
+func MergeConfigs(base, override []byte) ([]byte, error) {
+	var baseDoc, overrideDoc interface{}
+	if err := yaml.Unmarshal(base, &baseDoc); err != nil {
+		return nil, fmt.Errorf("could not parse the extended config: %w", err)
+	}
+	if err := yaml.Unmarshal(override, &overrideDoc); err != nil {
+		return nil, err
+	}
+
+	merged := mergeValues(baseDoc, overrideDoc)
+	if m, ok := merged.(map[string]interface{}); ok {
+		delete(m, "extends")
+	}
+	return yaml.Marshal(merged)
+}
+
+// mergeValues deep merges override onto base, without modifying either
+func mergeValues(base, override interface{}) interface{} {
+	overrides, ok := mappingOf(override)
+	if !ok {
+		return override
+	}
+	bases, _ := mappingOf(base)
+
+	merged := make(map[string]interface{}, len(bases)+len(overrides))
+	for key, value := range bases {
+		merged[key] = value
+	}
+	for key, value := range overrides {
+		if value == nil {
+			delete(merged, key)
+			continue
+		}
+		merged[key] = mergeValues(merged[key], value)
+	}
+	return merged
+}
+
+// mappingOf returns a decoded YAML mapping keyed by strings, or false if the value isn't a mapping. A mapping with any
+// key which isn't a string, such as a label named 1, is decoded as map[interface{}]interface{}.
+func mappingOf(value interface{}) (map[string]interface{}, bool) {
+	switch m := value.(type) {
+	case map[string]interface{}:
+		return m, true
+	case map[interface{}]interface{}:
+		mapping := make(map[string]interface{}, len(m))
+		for key, v := range m {
+			mapping[fmt.Sprint(key)] = v
+		}
+		return mapping, true
+	}
+	return nil, false
+}
@@ -0,0 +1,100 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseConfigReference(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    ConfigReference
+		wantErr string
+	}{
+		{value: "my-org/.github", want: ConfigReference{Owner: "my-org", Repo: ".github", Path: ".github/labeler.yml"}},
+		{value: "my-org/configs/labeler/base.yml", want: ConfigReference{Owner: "my-org", Repo: "configs", Path: "labeler/base.yml"}},
+		{value: "my-org/configs/labeler/base.yml@v1.2.0", want: ConfigReference{Owner: "my-org", Repo: "configs", Path: "labeler/base.yml", Ref: "v1.2.0"}},
+		{value: "my-org/configs@main", want: ConfigReference{Owner: "my-org", Repo: "configs", Path: ".github/labeler.yml", Ref: "main"}},
+		{value: "my-org", wantErr: `invalid extends "my-org": expected owner/repo/path@ref`},
+		{value: "my-org//labeler.yml", wantErr: `invalid extends "my-org//labeler.yml": expected owner/repo/path@ref`},
+		{value: "my-org/configs@", wantErr: `invalid extends "my-org/configs@": expected a ref following @`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseConfigReference(tt.value)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	reference := ConfigReference{Owner: "my-org", Repo: "configs", Path: "labeler.yml", Ref: "v1"}
+	assert.Equal(t, "my-org/configs/labeler.yml@v1", reference.String())
+}
+
+func TestExtendsOf(t *testing.T) {
+	assert.Equal(t, "my-org/.github", ExtendsOf([]byte("extends: my-org/.github\nlabels: {}\n")))
+	assert.Equal(t, "", ExtendsOf([]byte("labels: {}\n")))
+	assert.Equal(t, "", ExtendsOf([]byte("labels: [\n")))
+}
+
+func TestMergeConfigs(t *testing.T) {
+	base := []byte(`sync: true
+comments:
+  issues: 'Thanks!'
+  strategy: update
+labels:
+  'bug':
+    include: ['\bbug\b']
+    color: d73a4a
+  'question':
+    include: ['\?']
+  'wontfix':
+    include: ['\bwontfix\b']
+`)
+	override := []byte(`extends: my-org/.github
+comments:
+  issues: 'Thank you!'
+labels:
+  'bug':
+    include: ['\bbug[s]?\b', '\bcrash\b']
+  'wontfix': ~
+  'docs':
+    files:
+      include: ['docs/**']
+`)
+
+	b, err := MergeConfigs(base, override)
+	assert.NoError(t, err)
+
+	f := &FullConfig{}
+	assert.NoError(t, f.FromBytes(b))
+	assert.Empty(t, f.Extends)
+	assert.True(t, f.Sync, "values which aren't overridden are inherited")
+	assert.Equal(t, "Thank you!", *f.Comments.Issues)
+	assert.Equal(t, CommentStrategyUpdate, f.Comments.Strategy)
+	assert.Equal(t, map[string]Label{
+		"bug":      {Include: []string{`\bbug[s]?\b`, `\bcrash\b`}, Color: "d73a4a"},
+		"question": {Include: []string{`\?`}},
+		"docs":     {Files: &FileRule{Include: []string{"docs/**"}}},
+	}, f.Labels)
+}
+
+func TestMergeConfigs_yaml12(t *testing.T) {
+	b, err := MergeConfigs([]byte("labels:\n  'bug':\n    include: ['bug']\n"), []byte(`labels:
+  on:
+    include: [yes, no]
+`))
+	assert.NoError(t, err)
+
+	f := &FullConfig{}
+	assert.NoError(t, f.FromBytes(b))
+	assert.Equal(t, map[string]Label{
+		"bug": {Include: []string{"bug"}},
+		"on":  {Include: []string{"yes", "no"}},
+	}, f.Labels, "on, yes and no are strings rather than booleans")
+}
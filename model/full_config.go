@@ -2,12 +2,16 @@ package model
 
 import (
 	"errors"
-	"regexp"
+	"fmt"
+	"sort"
 	"strings"
 
-	"gopkg.in/yaml.v2"
+	"gopkg.in/yaml.v3"
 )
 
+// permissionRanks orders the repository permission levels reported by GitHub
+var permissionRanks = map[string]int{"none": 0, "read": 1, "write": 2, "admin": 3}
+
 type (
 	// Enable is a structure to hold options around enabling the labeler
 	Enable struct {
@@ -19,35 +23,111 @@ type (
 	Comments struct {
 		Issues       *string `yaml:"issues,omitempty" json:"issues,omitempty"`
 		PullRequests *string `yaml:"prs,omitempty" json:"prs,omitempty"`
+		// Strategy determines whether a new comment is added each time labels are applied. Defaults to CommentStrategyCreate.
+		Strategy CommentStrategy `yaml:"strategy,omitempty" json:"strategy,omitempty"`
+	}
+
+	// Commands configure the /label and /unlabel commands of issue and pull request comments
+	Commands struct {
+		// Permission is the minimum permission of a commenter to run commands: read, write or admin. Defaults to write.
+		Permission string `yaml:"permission,omitempty" json:"permission,omitempty"`
+	}
+
+	// FileRule holds glob patterns evaluated against the files changed by a pull request
+	FileRule struct {
+		Include []string `yaml:"include,omitempty,flow" json:"include,omitempty"`
+		Exclude []string `yaml:"exclude,omitempty,flow" json:"exclude,omitempty"`
 	}
 
 	// Label holds the rules around how labels will be applied
 	Label struct {
-		Include  []string `yaml:"include,omitempty,flow" json:"include,omitempty"`
-		Exclude  []string `yaml:"exclude,omitempty,flow" json:"exclude,omitempty"`
-		Branches []string `yaml:"branches,omitempty,flow" json:"branches,omitempty"`
+		Include  []string  `yaml:"include,omitempty,flow" json:"include,omitempty"`
+		Exclude  []string  `yaml:"exclude,omitempty,flow" json:"exclude,omitempty"`
+		Branches []string  `yaml:"branches,omitempty,flow" json:"branches,omitempty"`
+		Files    *FileRule `yaml:"files,omitempty" json:"files,omitempty"`
+		// HeadBranches optionally restrict the label to pull requests from a matching head branch
+		HeadBranches []string `yaml:"headBranches,omitempty,flow" json:"headBranches,omitempty"`
+		// Authors optionally restrict the label to (or suppress it for) the users who opened the issue or pull request
+		Authors *AuthorRule `yaml:"authors,omitempty" json:"authors,omitempty"`
+		// Color is the hexadecimal color of the repository label (e.g. d73a4a), used when creating or updating it
+		Color string `yaml:"color,omitempty" json:"color,omitempty"`
+		// Description is the description of the repository label, used when creating or updating it
+		Description string `yaml:"description,omitempty" json:"description,omitempty"`
 	}
 
 	// FullConfig is the container defining how the configuration object is structured
 	FullConfig struct {
+		// Kind optionally declares the schema of the config, which is otherwise detected from its structure: full
+		Kind string `yaml:"kind,omitempty" json:"kind,omitempty"`
+		// Extends optionally references a config to inherit from, as owner/repo/path@ref (see ConfigReference)
+		Extends  string           `yaml:"extends,omitempty" json:"extends,omitempty"`
 		Enable   *Enable          `yaml:"enable,omitempty" json:"enable,omitempty"`
 		Comments *Comments        `yaml:"comments,omitempty" json:"comments,omitempty"`
 		Labels   map[string]Label `yaml:"labels,flow" json:"labels,omitempty"`
 		Fields   []string         `yaml:"fields,omitempty,flow" json:"fields,omitempty"`
+		Sync     bool             `yaml:"sync,omitempty" json:"sync,omitempty"`
+		// Conventional optionally derives labels from Conventional Commits pull request titles
+		Conventional *Conventional `yaml:"conventional,omitempty" json:"conventional,omitempty"`
+		// Size optionally labels pull requests by the number of changed lines
+		Size *Size `yaml:"size,omitempty" json:"size,omitempty"`
+		// Commands optionally configure the slash commands of issue and pull request comments
+		Commands *Commands `yaml:"commands,omitempty" json:"commands,omitempty"`
+
+		rules     *RuleSet
+		templates *commentTemplates
+		// partial is true for one of the configs of a config directory, which needn't define labels of its own
+		partial bool
 	}
 )
 
-// FromBytes is used to parse bytes into the Config instance
+// FromBytes is used to parse bytes into the Config instance. All label patterns are compiled, and every problem, from
+// YAML type errors to invalid patterns, is reported together as ConfigErrors. A config which extends another needn't
+// define labels of its own.
 func (f *FullConfig) FromBytes(b []byte) error {
-	err := yaml.Unmarshal(b, &f)
-	if err != nil {
+	var problems ConfigErrors
+	if err := yaml.Unmarshal(b, f); err != nil {
+		problems = decodeErrors(b, err)
+	}
+	if len(problems) == 0 && len(f.Labels) == 0 && f.Extends == "" && !f.partial {
+		problems = append(problems, ConfigError{Path: "/labels", Message: "full config requires labels to be defined"})
+	}
+	if problem := kindProblem(f.Kind, KindFull); problem != "" {
+		problems = append(problems, ConfigError{Path: "/kind", Message: problem})
+	}
+
+	var err error
+	var ruleProblems ConfigErrors
+	f.rules, err = NewRuleSet(f.Labels)
+	if err != nil && !errors.As(err, &ruleProblems) {
 		return err
 	}
+	problems = append(problems, ruleProblems...)
 
-	if len(f.Labels) == 0 {
-		return errors.New("full config requires labels to be defined")
+	if f.Extends != "" {
+		if _, err := ParseConfigReference(f.Extends); err != nil {
+			problems = append(problems, ConfigError{Path: "/extends", Message: err.Error()})
+		}
 	}
 
+	f.templates = f.compileComments(&problems)
+	if f.Size != nil {
+		f.Size.compile(&problems)
+	}
+	if f.Commands != nil && f.Commands.Permission != "" && PermissionRank(f.Commands.Permission) <= 0 {
+		problems = append(problems, ConfigError{
+			Path:    "/commands/permission",
+			Message: fmt.Sprintf("invalid permission %q: expected one of read, write, admin", f.Commands.Permission),
+		})
+	}
+	if f.Comments != nil && !f.Comments.Strategy.Valid() {
+		problems = append(problems, ConfigError{
+			Path:    "/comments/strategy",
+			Message: fmt.Sprintf("invalid comment strategy %q: expected one of create, update, once", f.Comments.Strategy),
+		})
+	}
+	if len(problems) > 0 {
+		return locateConfigErrors(b, problems)
+	}
 	return nil
 }
 
@@ -65,31 +145,89 @@ func (f *FullConfig) IncludedFields() []string {
 
 // LabelsFor allows config implementations to determine the labels to be applied to the input strings
 func (f *FullConfig) LabelsFor(text ...string) map[string]Label {
-	searchable := []byte(strings.Join(text, " "))
-	labels := make(map[string]Label)
-	for key, values := range f.Labels {
-		excluded := false
-		for _, pattern := range values.Exclude {
-			re := regexp.MustCompile(pattern)
-			if re.Match(searchable) {
-				excluded = true
-				break
-			}
-		}
+	return f.Rules().LabelsFor(text...)
+}
 
-		if excluded {
-			break
-		}
+// Explain evaluates every label against the named fields, describing which patterns matched or suppressed each label
+func (f *FullConfig) Explain(fields ...Field) []Explanation {
+	return f.Rules().Explain(fields...)
+}
 
-		for _, pattern := range values.Include {
-			re := regexp.MustCompile(pattern)
-			if re.Match(searchable) {
-				labels[key] = values
-				break
-			}
+// Rules returns the compiled rules of this config. Rules are compiled by FromBytes; a config constructed in code is
+// compiled on first use, omitting any invalid patterns.
+func (f *FullConfig) Rules() *RuleSet {
+	if f.rules == nil {
+		f.rules, _ = NewRuleSet(f.Labels)
+	}
+	return f.rules
+}
+
+// IssueComment returns the comment template for issues, or nil if there is none. Templates are parsed by FromBytes;
+// an invalid template of a config constructed in code is rendered as plain text.
+func (f *FullConfig) IssueComment() *CommentTemplate {
+	if f.templates == nil {
+		f.templates = f.compileComments(nil)
+	}
+	return f.templates.issues
+}
+
+// PullRequestComment returns the comment template for pull requests, or nil if there is none. Templates are parsed by
+// FromBytes; an invalid template of a config constructed in code is rendered as plain text.
+func (f *FullConfig) PullRequestComment() *CommentTemplate {
+	if f.templates == nil {
+		f.templates = f.compileComments(nil)
+	}
+	return f.templates.prs
+}
+
+// MatchTitle derives labels from a Conventional Commits title, if the conventional matcher is configured. A config
+// without the matcher matches nothing, and parses every title.
+func (f *FullConfig) MatchTitle(title string) (map[string][]Match, bool) {
+	if f.Conventional == nil {
+		return nil, true
+	}
+	return f.Conventional.Match(title)
+}
+
+// CommentStrategy returns the configured comment strategy, or CommentStrategyCreate if there is none
+func (f *FullConfig) CommentStrategy() CommentStrategy {
+	if f.Comments == nil || f.Comments.Strategy == "" {
+		return CommentStrategyCreate
+	}
+	return f.Comments.Strategy
+}
+
+// CommandPermission returns the minimum permission of a commenter to run slash commands, or write if there is none
+func (f *FullConfig) CommandPermission() string {
+	if f.Commands == nil || f.Commands.Permission == "" {
+		return "write"
+	}
+	return strings.ToLower(f.Commands.Permission)
+}
+
+// ManagedLabels returns the names of all labels declared in this config, including those derived from Conventional
+// Commits titles
+func (f *FullConfig) ManagedLabels() []string {
+	names := make([]string, 0, len(f.Labels))
+	for name := range f.Labels {
+		names = append(names, name)
+	}
+	for _, name := range f.Conventional.Labels() {
+		if _, ok := f.Labels[name]; !ok {
+			names = append(names, name)
 		}
 	}
-	return labels
+	return names
+}
+
+// IssuesEnabled returns true unless labeling of issues has been explicitly disabled
+func (e *Enable) IssuesEnabled() bool {
+	return e == nil || e.Issues == nil || *e.Issues
+}
+
+// PullRequestsEnabled returns true unless labeling of pull requests has been explicitly disabled
+func (e *Enable) PullRequestsEnabled() bool {
+	return e == nil || e.PullRequests == nil || *e.PullRequests
 }
 
 // Ptr gets the pointer to an Enable object
@@ -98,8 +236,42 @@ func (e Enable) Ptr() *Enable { return &e }
 // Ptr gets the pointer to a Comments object
 func (c Comments) Ptr() *Comments { return &c }
 
+// NormalizedColor returns the label's color without a leading '#', in lower case as reported by the GitHub API
+func (l Label) NormalizedColor() string {
+	return strings.ToLower(strings.TrimPrefix(l.Color, "#"))
+}
+
 // Ptr gets the pointer to a Label object
 func (l Label) Ptr() *Label { return &l }
 
 // Ptr gets the pointer to a FullConfig object
 func (f FullConfig) Ptr() *FullConfig { return &f }
+
+// compileComments parses the comments for issues and pull requests, appending a ConfigError for each invalid template
+func (f *FullConfig) compileComments(problems *ConfigErrors) *commentTemplates {
+	templates := &commentTemplates{}
+	if f.Comments != nil {
+		templates.issues = compileComment(f.Comments.Issues, "/comments/issues", problems)
+		templates.prs = compileComment(f.Comments.PullRequests, "/comments/prs", problems)
+	}
+	return templates
+}
+
+// PermissionRank orders a repository permission level (none, read, write, admin), so that a higher rank includes
+// every lower rank. Unknown levels rank below none.
+func PermissionRank(permission string) int {
+	if rank, ok := permissionRanks[strings.ToLower(permission)]; ok {
+		return rank
+	}
+	return -1
+}
+
+// sortedLabelNames returns the keys of labels in lexical order
+func sortedLabelNames(labels map[string]Label) []string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
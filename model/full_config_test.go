@@ -1,6 +1,7 @@
 package model
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -123,3 +124,135 @@ func TestFullConfig_FromBytes(t *testing.T) {
 		})
 	}
 }
+
+func TestFullConfig_FromBytes_invalidPatterns(t *testing.T) {
+	f := &FullConfig{}
+	err := f.FromBytes([]byte(`labels:
+  'bug':
+    include:
+      - '(unclosed'
+      - 'bug'
+    branches: ['[main']
+  'docs':
+    files:
+      include: ['docs/[a']
+`))
+
+	var problems ConfigErrors
+	if !errors.As(err, &problems) {
+		t.Fatalf("expected ConfigErrors, got %v", err)
+	}
+	assert.Equal(t, ConfigErrors{
+		{Path: "/labels/bug/include/0", Line: 4, Column: 9, Message: "invalid regular expression \"(unclosed\": error parsing regexp: missing closing ): `(unclosed`"},
+		{Path: "/labels/bug/branches/0", Line: 6, Column: 16, Message: "invalid regular expression \"[main\": error parsing regexp: missing closing ]: `[main`"},
+		{Path: "/labels/docs/files/include/0", Line: 9, Column: 17, Message: "invalid glob \"docs/[a\": unterminated character class"},
+	}, problems)
+}
+
+func TestFullConfig_LabelsFor_excludedLabelPrecedesMatch(t *testing.T) {
+	f := &FullConfig{}
+	assert.NoError(t, f.FromBytes([]byte(`labels:
+  'bug':
+    include: ['\bbug\b']
+    exclude: ['\[wip\]']
+  'question':
+    include: ['\?']
+`)))
+
+	// labels are evaluated in lexical order; excluding 'bug' must not stop 'question' from being evaluated
+	got := f.LabelsFor("[wip] is this a bug?")
+	assert.Len(t, got, 1)
+	assert.Contains(t, got, "question")
+}
+
+func TestFullConfig_CommentStrategy(t *testing.T) {
+	f := &FullConfig{}
+	assert.NoError(t, f.FromBytes([]byte(`comments:
+  issues: 'Thanks!'
+labels:
+  'bug':
+    include: ['bug']
+`)))
+	assert.Equal(t, CommentStrategyCreate, f.CommentStrategy(), "strategy defaults to create")
+
+	f = &FullConfig{}
+	assert.NoError(t, f.FromBytes([]byte(`comments:
+  strategy: update
+labels:
+  'bug':
+    include: ['bug']
+`)))
+	assert.Equal(t, CommentStrategyUpdate, f.CommentStrategy())
+
+	f = &FullConfig{}
+	err := f.FromBytes([]byte(`comments:
+  strategy: sometimes
+labels:
+  'bug':
+    include: ['bug']
+`))
+	var problems ConfigErrors
+	if !errors.As(err, &problems) {
+		t.Fatalf("expected ConfigErrors, got %v", err)
+	}
+	assert.Equal(t, ConfigErrors{
+		{Path: "/comments/strategy", Line: 2, Column: 3, Message: `invalid comment strategy "sometimes": expected one of create, update, once`},
+	}, problems)
+}
+
+func TestFullConfig_CommandPermission(t *testing.T) {
+	f := &FullConfig{}
+	assert.NoError(t, f.FromBytes([]byte(`labels:
+  'bug':
+    include: ['bug']
+`)))
+	assert.Equal(t, "write", f.CommandPermission(), "permission defaults to write")
+
+	f = &FullConfig{}
+	assert.NoError(t, f.FromBytes([]byte(`commands:
+  permission: Admin
+labels:
+  'bug':
+    include: ['bug']
+`)))
+	assert.Equal(t, "admin", f.CommandPermission())
+
+	f = &FullConfig{}
+	err := f.FromBytes([]byte(`commands:
+  permission: none
+labels:
+  'bug':
+    include: ['bug']
+`))
+	var problems ConfigErrors
+	if !errors.As(err, &problems) {
+		t.Fatalf("expected ConfigErrors, got %v", err)
+	}
+	assert.Equal(t, ConfigErrors{
+		{Path: "/commands/permission", Line: 2, Column: 3, Message: `invalid permission "none": expected one of read, write, admin`},
+	}, problems)
+}
+
+func TestEnable_enabled(t *testing.T) {
+	btrue := true
+	bfalse := false
+	tests := []struct {
+		name             string
+		enable           *Enable
+		wantIssues       bool
+		wantPullRequests bool
+	}{
+		{"nil enables everything", nil, true, true},
+		{"empty enables everything", &Enable{}, true, true},
+		{"issues only", &Enable{Issues: &btrue, PullRequests: &bfalse}, true, false},
+		{"prs only", &Enable{Issues: &bfalse, PullRequests: &btrue}, false, true},
+		{"omitted issues defaults to enabled", &Enable{PullRequests: &bfalse}, true, false},
+		{"omitted prs defaults to enabled", &Enable{Issues: &bfalse}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantIssues, tt.enable.IssuesEnabled())
+			assert.Equal(t, tt.wantPullRequests, tt.enable.PullRequestsEnabled())
+		})
+	}
+}
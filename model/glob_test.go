@@ -0,0 +1,43 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_globToRegexp(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"literal match", "README.md", "README.md", true},
+		{"literal mismatch", "README.md", "docs/README.md", false},
+		{"single star stays in directory", "docs/*", "docs/index.md", true},
+		{"single star does not cross directories", "docs/*", "docs/api/index.md", false},
+		{"double star crosses directories", "docs/**", "docs/api/index.md", true},
+		{"leading double star matches root", "**/*.md", "README.md", true},
+		{"leading double star matches nested", "**/*.md", "docs/api/index.md", true},
+		{"middle double star matches zero directories", "cmd/**/main.go", "cmd/main.go", true},
+		{"middle double star matches many directories", "cmd/**/main.go", "cmd/a/b/main.go", true},
+		{"question mark", "file?.go", "file1.go", true},
+		{"question mark does not match separator", "a?b", "a/b", false},
+		{"character class", "file[0-9].go", "file7.go", true},
+		{"negated character class", "file[!0-9].go", "file7.go", false},
+		{"dots are literal", "*.go", "main_go", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			re, err := globToRegexp(tt.pattern)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, re.MatchString(tt.path), "pattern %q against %q", tt.pattern, tt.path)
+		})
+	}
+}
+
+func Test_globToRegexp_invalid(t *testing.T) {
+	_, err := globToRegexp("file[0-9.go")
+	assert.Error(t, err)
+}
@@ -10,14 +10,31 @@ type Client interface {
 	// DownloadContents downloads the contents of a file from a repository. (implementation of github.RepositoriesService.DownloadContents)
 	DownloadContents(ctx context.Context, owner, repo, filepath string, opts *github.RepositoryContentGetOptions) (io.ReadCloser, *github.Response, error)
 
+	// GetContents retrieves the metadata and contents of a file, or the entries of a directory, of a repository.
+	// (implementation of github.RepositoriesService.GetContents)
+	GetContents(ctx context.Context, owner, repo, path string, opts *github.RepositoryContentGetOptions) (
+		*github.RepositoryContent, []*github.RepositoryContent, *github.Response, error)
+
 	// CreateComment creates a comment on the specified issue. Specifying an issue number of 0 will create a comment on the repository.
 	// (implementation of github.IssuesService.CreateComment)
 	CreateComment(ctx context.Context, owner string, repo string, number int, comment *github.IssueComment) (*github.IssueComment, *github.Response, error)
 
+	// ListComments retrieves all comments on the specified issue, following pagination until exhausted.
+	// (implementation of github.IssuesService.ListComments)
+	ListComments(ctx context.Context, owner string, repo string, number int) ([]*github.IssueComment, *github.Response, error)
+
+	// EditComment edits the specified issue comment.
+	// (implementation of github.IssuesService.EditComment)
+	EditComment(ctx context.Context, owner string, repo string, commentID int64, comment *github.IssueComment) (*github.IssueComment, *github.Response, error)
+
 	// AddLabelsToIssue adds labels to the specified issue. Specifying an issue number of 0 will add labels to the repository.
 	// (implementation of github.IssuesService.AddLabelsToIssue)
 	AddLabelsToIssue(ctx context.Context, owner string, repo string, number int, labels []string) ([]*github.Label, *github.Response, error)
 
+	// RemoveLabelForIssue removes a label from the specified issue.
+	// (implementation of github.IssuesService.RemoveLabelForIssue)
+	RemoveLabelForIssue(ctx context.Context, owner string, repo string, number int, label string) (*github.Response, error)
+
 	// GetIssue retrieves the specified issue. Specifying an issue number of 0 will return the repository's default issue.
 	// (implementation of github.IssuesService.Get)
 	GetIssue(ctx context.Context, owner string, repo string, number int) (*github.Issue, *github.Response, error)
@@ -25,6 +42,34 @@ type Client interface {
 	// GetPullRequest retrieves the specified pull request. Specifying a pull request number of 0 will return the repository's default pull request.
 	// (implementation of github.PullRequestsService.Get)
 	GetPullRequest(ctx context.Context, owner string, repo string, number int) (*github.PullRequest, *github.Response, error)
+
+	// ListRepositoryIssues retrieves all issues and pull requests of a repository matching opts, following pagination until exhausted.
+	// (implementation of github.IssuesService.ListByRepo)
+	ListRepositoryIssues(ctx context.Context, owner string, repo string, opts *github.IssueListByRepoOptions) ([]*github.Issue, *github.Response, error)
+
+	// ListPullRequestFiles retrieves all files changed by the specified pull request, following pagination until exhausted.
+	// (implementation of github.PullRequestsService.ListFiles)
+	ListPullRequestFiles(ctx context.Context, owner string, repo string, number int) ([]*github.CommitFile, *github.Response, error)
+
+	// ListLabels retrieves all labels of a repository, following pagination until exhausted.
+	// (implementation of github.IssuesService.ListLabels)
+	ListLabels(ctx context.Context, owner string, repo string) ([]*github.Label, *github.Response, error)
+
+	// CreateLabel creates a new label on the specified repository.
+	// (implementation of github.IssuesService.CreateLabel)
+	CreateLabel(ctx context.Context, owner string, repo string, label *github.Label) (*github.Label, *github.Response, error)
+
+	// EditLabel edits the named label of the specified repository.
+	// (implementation of github.IssuesService.EditLabel)
+	EditLabel(ctx context.Context, owner string, repo string, name string, label *github.Label) (*github.Label, *github.Response, error)
+
+	// GetPermissionLevel retrieves the permission level of a user on the specified repository (e.g. admin, write, read, none).
+	// (implementation of github.RepositoriesService.GetPermissionLevel)
+	GetPermissionLevel(ctx context.Context, owner string, repo string, user string) (*github.RepositoryPermissionLevel, *github.Response, error)
+
+	// GetAuthenticatedUser retrieves the user the client is authenticated as. GitHub App installations are not users, and fail.
+	// (implementation of github.UsersService.Get with an empty user)
+	GetAuthenticatedUser(ctx context.Context) (*github.User, *github.Response, error)
 }
 
 // RichClient is a wrapper around the github.Client that provides additional methods for downloading contents, creating
@@ -41,6 +86,16 @@ func (r *RichClient) DownloadContents(ctx context.Context, owner, repo, filepath
 	return r.Repositories.DownloadContents(ctx, owner, repo, filepath, opts)
 }
 
+// GetContents retrieves the metadata and contents of a file, or the entries of a directory, of a repository. It implements
+// the github.RepositoriesService.GetContents method.
+func (r *RichClient) GetContents(ctx context.Context, owner, repo, path string, opts *github.RepositoryContentGetOptions) (
+	*github.RepositoryContent, []*github.RepositoryContent, *github.Response, error) {
+	if r.Repositories == nil {
+		return nil, nil, nil, nil
+	}
+	return r.Repositories.GetContents(ctx, owner, repo, path, opts)
+}
+
 // CreateComment creates a comment on the specified issue. It implements the github.IssuesService.CreateComment method.
 func (r *RichClient) CreateComment(ctx context.Context, owner string, repo string, number int, comment *github.IssueComment) (*github.IssueComment, *github.Response, error) {
 	if r.Issues == nil {
@@ -49,6 +104,35 @@ func (r *RichClient) CreateComment(ctx context.Context, owner string, repo strin
 	return r.Issues.CreateComment(ctx, owner, repo, number, comment)
 }
 
+// ListComments retrieves all comments on the specified issue. It implements the github.IssuesService.ListComments method,
+// requesting each page until the last page has been read.
+func (r *RichClient) ListComments(ctx context.Context, owner string, repo string, number int) ([]*github.IssueComment, *github.Response, error) {
+	if r.Issues == nil {
+		return nil, nil, nil
+	}
+	var all []*github.IssueComment
+	opts := &github.IssueListCommentsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		comments, resp, err := r.Issues.ListComments(ctx, owner, repo, number, opts)
+		if err != nil {
+			return nil, resp, err
+		}
+		all = append(all, comments...)
+		if resp == nil || resp.NextPage == 0 {
+			return all, resp, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// EditComment edits the specified issue comment. It implements the github.IssuesService.EditComment method.
+func (r *RichClient) EditComment(ctx context.Context, owner string, repo string, commentID int64, comment *github.IssueComment) (*github.IssueComment, *github.Response, error) {
+	if r.Issues == nil {
+		return nil, nil, nil
+	}
+	return r.Issues.EditComment(ctx, owner, repo, commentID, comment)
+}
+
 // AddLabelsToIssue adds labels to the specified issue. It implements the github.IssuesService.AddLabelsToIssue method.
 func (r *RichClient) AddLabelsToIssue(ctx context.Context, owner string, repo string, number int, labels []string) ([]*github.Label, *github.Response, error) {
 	if r.Issues == nil {
@@ -57,6 +141,14 @@ func (r *RichClient) AddLabelsToIssue(ctx context.Context, owner string, repo st
 	return r.Issues.AddLabelsToIssue(ctx, owner, repo, number, labels)
 }
 
+// RemoveLabelForIssue removes a label from the specified issue. It implements the github.IssuesService.RemoveLabelForIssue method.
+func (r *RichClient) RemoveLabelForIssue(ctx context.Context, owner string, repo string, number int, label string) (*github.Response, error) {
+	if r.Issues == nil {
+		return nil, nil
+	}
+	return r.Issues.RemoveLabelForIssue(ctx, owner, repo, number, label)
+}
+
 // GetIssue retrieves the specified issue. It implements the github.IssuesService.Get method.
 func (r *RichClient) GetIssue(ctx context.Context, owner string, repo string, number int) (*github.Issue, *github.Response, error) {
 	if r.Issues == nil {
@@ -72,3 +164,106 @@ func (r *RichClient) GetPullRequest(ctx context.Context, owner string, repo stri
 	}
 	return r.PullRequests.Get(ctx, owner, repo, number)
 }
+
+// ListPullRequestFiles retrieves all files changed by the specified pull request. It implements the github.PullRequestsService.ListFiles method,
+// requesting each page until the last page has been read.
+func (r *RichClient) ListPullRequestFiles(ctx context.Context, owner string, repo string, number int) ([]*github.CommitFile, *github.Response, error) {
+	if r.PullRequests == nil {
+		return nil, nil, nil
+	}
+	var all []*github.CommitFile
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		files, resp, err := r.PullRequests.ListFiles(ctx, owner, repo, number, opts)
+		if err != nil {
+			return nil, resp, err
+		}
+		all = append(all, files...)
+		if resp == nil || resp.NextPage == 0 {
+			return all, resp, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// ListRepositoryIssues retrieves all issues and pull requests of a repository matching opts. It implements the
+// github.IssuesService.ListByRepo method, requesting each page until the last page has been read.
+func (r *RichClient) ListRepositoryIssues(ctx context.Context, owner string, repo string, opts *github.IssueListByRepoOptions) ([]*github.Issue, *github.Response, error) {
+	if r.Issues == nil {
+		return nil, nil, nil
+	}
+	listOpts := github.IssueListByRepoOptions{}
+	if opts != nil {
+		listOpts = *opts
+	}
+	if listOpts.PerPage == 0 {
+		listOpts.PerPage = 100
+	}
+	var all []*github.Issue
+	for {
+		issues, resp, err := r.Issues.ListByRepo(ctx, owner, repo, &listOpts)
+		if err != nil {
+			return nil, resp, err
+		}
+		all = append(all, issues...)
+		if resp == nil || resp.NextPage == 0 {
+			return all, resp, nil
+		}
+		listOpts.Page = resp.NextPage
+	}
+}
+
+// ListLabels retrieves all labels of a repository. It implements the github.IssuesService.ListLabels method, requesting
+// each page until the last page has been read.
+func (r *RichClient) ListLabels(ctx context.Context, owner string, repo string) ([]*github.Label, *github.Response, error) {
+	if r.Issues == nil {
+		return nil, nil, nil
+	}
+	var all []*github.Label
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		labels, resp, err := r.Issues.ListLabels(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, resp, err
+		}
+		all = append(all, labels...)
+		if resp == nil || resp.NextPage == 0 {
+			return all, resp, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// CreateLabel creates a new label on the specified repository. It implements the github.IssuesService.CreateLabel method.
+func (r *RichClient) CreateLabel(ctx context.Context, owner string, repo string, label *github.Label) (*github.Label, *github.Response, error) {
+	if r.Issues == nil {
+		return nil, nil, nil
+	}
+	return r.Issues.CreateLabel(ctx, owner, repo, label)
+}
+
+// EditLabel edits the named label of the specified repository. It implements the github.IssuesService.EditLabel method.
+func (r *RichClient) EditLabel(ctx context.Context, owner string, repo string, name string, label *github.Label) (*github.Label, *github.Response, error) {
+	if r.Issues == nil {
+		return nil, nil, nil
+	}
+	return r.Issues.EditLabel(ctx, owner, repo, name, label)
+}
+
+// GetPermissionLevel retrieves the permission level of a user on the specified repository. It implements the
+// github.RepositoriesService.GetPermissionLevel method.
+func (r *RichClient) GetPermissionLevel(ctx context.Context, owner string, repo string, user string) (*github.RepositoryPermissionLevel, *github.Response, error) {
+	if r.Repositories == nil {
+		return nil, nil, nil
+	}
+	return r.Repositories.GetPermissionLevel(ctx, owner, repo, user)
+}
+
+// GetAuthenticatedUser retrieves the user the client is authenticated as. It implements the github.UsersService.Get
+// method with an empty user.
+func (r *RichClient) GetAuthenticatedUser(ctx context.Context) (*github.User, *github.Response, error) {
+	if r.Users == nil {
+		return nil, nil, nil
+	}
+	return r.Users.Get(ctx, "")
+}
@@ -0,0 +1,299 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// labelColor matches the hexadecimal colors accepted by GitHub, optionally prefixed by '#'
+var labelColor = regexp.MustCompile(`^#?[0-9a-fA-F]{6}$`)
+
+type (
+	// pattern is a compiled regular expression or glob, retaining its source for explanations
+	pattern struct {
+		source string
+		re     *regexp.Regexp
+	}
+
+	// Rule is the compiled form of a single Label
+	Rule struct {
+		// Name is the label applied by this rule
+		Name string
+		// Label is the uncompiled definition of this rule
+		Label Label
+
+		include      []pattern
+		exclude      []pattern
+		branches     []pattern
+		headBranches []pattern
+		filesInclude []pattern
+		filesExclude []pattern
+	}
+
+	// RuleSet is a set of compiled label rules. It is built once when a config is loaded and reused across evaluations.
+	RuleSet struct {
+		rules  []*Rule
+		byName map[string]*Rule
+	}
+)
+
+// NewRuleSet compiles every pattern of the provided labels. All invalid patterns are reported together as ConfigErrors,
+// with each ConfigError.Path referencing the offending pattern. The returned RuleSet is always usable; rules omit any
+// pattern which failed to compile.
+func NewRuleSet(labels map[string]Label) (*RuleSet, error) {
+	r := &RuleSet{byName: make(map[string]*Rule, len(labels))}
+	var problems ConfigErrors
+
+	for _, name := range sortedLabelNames(labels) {
+		label := labels[name]
+		rule := &Rule{Name: name, Label: label}
+		prefix := "/labels/" + escapePointer(name)
+
+		rule.include = compilePatterns(label.Include, prefix+"/include", compileRegexp, &problems)
+		rule.exclude = compilePatterns(label.Exclude, prefix+"/exclude", compileRegexp, &problems)
+		rule.branches = compilePatterns(label.Branches, prefix+"/branches", compileRegexp, &problems)
+		rule.headBranches = compilePatterns(label.HeadBranches, prefix+"/headBranches", compileRegexp, &problems)
+		if label.Color != "" && !labelColor.MatchString(label.Color) {
+			problems = append(problems, ConfigError{
+				Path:    prefix + "/color",
+				Message: fmt.Sprintf("invalid color %q: expected six hexadecimal digits, e.g. d73a4a", label.Color),
+			})
+		}
+		if label.Authors != nil {
+			compileAuthors(label.Authors.Include, prefix+"/authors/include", &problems)
+			compileAuthors(label.Authors.Exclude, prefix+"/authors/exclude", &problems)
+		}
+		if label.Files != nil {
+			rule.filesInclude = compilePatterns(label.Files.Include, prefix+"/files/include", globToRegexp, &problems)
+			rule.filesExclude = compilePatterns(label.Files.Exclude, prefix+"/files/exclude", globToRegexp, &problems)
+		}
+
+		r.rules = append(r.rules, rule)
+		r.byName[name] = rule
+	}
+
+	if len(problems) > 0 {
+		return r, problems
+	}
+	return r, nil
+}
+
+// Names returns the name of every rule in lexical order
+func (r *RuleSet) Names() []string {
+	names := make([]string, 0, len(r.rules))
+	for _, rule := range r.rules {
+		names = append(names, rule.Name)
+	}
+	return names
+}
+
+// Rule returns the rule for the named label, or nil if no such rule exists
+func (r *RuleSet) Rule(name string) *Rule {
+	if r == nil {
+		return nil
+	}
+	return r.byName[name]
+}
+
+// HasFileRules returns true if any rule is evaluated against changed files
+func (r *RuleSet) HasFileRules() bool {
+	for _, rule := range r.rules {
+		if len(rule.filesInclude) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// LabelsFor determines the labels with an include pattern matching the input strings, and no matching exclude pattern
+func (r *RuleSet) LabelsFor(text ...string) map[string]Label {
+	searchable := strings.Join(text, " ")
+	labels := make(map[string]Label)
+	for _, rule := range r.rules {
+		if firstMatch(rule.exclude, searchable) != nil {
+			continue
+		}
+		if firstMatch(rule.include, searchable) != nil {
+			labels[rule.Name] = rule.Label
+		}
+	}
+	return labels
+}
+
+// LabelsForFiles determines the labels with a file rule matching any of the changed files
+func (r *RuleSet) LabelsForFiles(files ...string) map[string]Label {
+	labels := make(map[string]Label)
+	for _, rule := range r.rules {
+		if rule.MatchFiles(files...) != nil {
+			labels[rule.Name] = rule.Label
+		}
+	}
+	return labels
+}
+
+// LabelsForAuthor determines the labels which are applied by author alone: rules with an author include matching the
+// author, and no include patterns or file rules
+func (r *RuleSet) LabelsForAuthor(author Author) map[string]Label {
+	labels := make(map[string]Label)
+	for _, rule := range r.rules {
+		if len(rule.include) > 0 || len(rule.filesInclude) > 0 {
+			continue
+		}
+		if rule.MatchAuthor(author) != nil {
+			labels[rule.Name] = rule.Label
+		}
+	}
+	return labels
+}
+
+// LabelsForHeadBranch determines the labels which are applied by head branch alone: rules with a head branch pattern
+// matching the head branch, and no include patterns or file rules
+func (r *RuleSet) LabelsForHeadBranch(headBranch string) map[string]Label {
+	labels := make(map[string]Label)
+	for _, rule := range r.rules {
+		if len(rule.include) > 0 || len(rule.filesInclude) > 0 {
+			continue
+		}
+		if rule.MatchHeadBranch(headBranch) != nil {
+			labels[rule.Name] = rule.Label
+		}
+	}
+	return labels
+}
+
+// Explain evaluates every rule against the named fields, describing which patterns matched or suppressed each label
+func (r *RuleSet) Explain(fields ...Field) []Explanation {
+	searchable := newSearchText(fields)
+	explanations := make([]Explanation, 0, len(r.rules))
+	for _, rule := range r.rules {
+		explanation := Explanation{Label: rule.Name}
+		for _, p := range rule.include {
+			if m, ok := searchable.find(p.source, p.re); ok {
+				explanation.Matches = append(explanation.Matches, m)
+			}
+		}
+		if len(explanation.Matches) > 0 {
+			for _, p := range rule.exclude {
+				if m, ok := searchable.find(p.source, p.re); ok {
+					explanation.Suppressed = fmt.Sprintf("excluded by %s", m)
+					break
+				}
+			}
+		}
+		explanations = append(explanations, explanation)
+	}
+	return explanations
+}
+
+// BranchAllowed determines whether the rule may be applied for the target branch. Rules without branch restrictions
+// (including a nil rule) are always allowed; restricted rules require a target branch matching one of the patterns.
+func (r *Rule) BranchAllowed(targetBranch string) bool {
+	if r == nil || len(r.Label.Branches) == 0 {
+		return true
+	}
+	if targetBranch == "" {
+		return false
+	}
+	return firstMatch(r.branches, targetBranch) != nil
+}
+
+// HeadBranchAllowed determines whether the rule may be applied for the head branch, with the same semantics as
+// BranchAllowed: restricted rules require a head branch matching one of the patterns.
+func (r *Rule) HeadBranchAllowed(headBranch string) bool {
+	if r == nil || len(r.Label.HeadBranches) == 0 {
+		return true
+	}
+	return r.MatchHeadBranch(headBranch) != nil
+}
+
+// MatchHeadBranch returns a match for the head branch pattern matching the head branch, or nil if none match
+func (r *Rule) MatchHeadBranch(headBranch string) *Match {
+	if r == nil || headBranch == "" {
+		return nil
+	}
+	p := firstMatch(r.headBranches, headBranch)
+	if p == nil {
+		return nil
+	}
+	loc := p.re.FindStringIndex(headBranch)
+	return &Match{Field: "head_branch", Pattern: p.source, Text: headBranch[loc[0]:loc[1]], Start: loc[0], End: loc[1]}
+}
+
+// AuthorAllowed determines whether the rule may be applied for the author. Rules without author restrictions
+// (including a nil rule) are always allowed; an excluded author never is, and author includes require a matching entry.
+func (r *Rule) AuthorAllowed(author Author) bool {
+	if r == nil || r.Label.Authors == nil {
+		return true
+	}
+	if r.AuthorExcluded(author) {
+		return false
+	}
+	return len(r.Label.Authors.Include) == 0 || author.match(r.Label.Authors.Include) != ""
+}
+
+// AuthorExcluded returns true if the author matches an author exclude entry of the rule
+func (r *Rule) AuthorExcluded(author Author) bool {
+	return r != nil && r.Label.Authors != nil && author.match(r.Label.Authors.Exclude) != ""
+}
+
+// MatchAuthor returns a match for the author include entry matching the author, or nil if none match
+func (r *Rule) MatchAuthor(author Author) *Match {
+	if r == nil || r.Label.Authors == nil {
+		return nil
+	}
+	entry := author.match(r.Label.Authors.Include)
+	if entry == "" {
+		return nil
+	}
+	return &Match{Field: "author", Pattern: entry, Text: author.Login, Start: 0, End: len(author.Login)}
+}
+
+// MatchFiles returns the first file matching an include glob without also matching an exclude glob, or nil if none match
+func (r *Rule) MatchFiles(files ...string) *Match {
+	if r == nil || len(r.filesInclude) == 0 {
+		return nil
+	}
+	for _, file := range files {
+		if firstMatch(r.filesExclude, file) != nil {
+			continue
+		}
+		if p := firstMatch(r.filesInclude, file); p != nil {
+			return &Match{Field: "files", Pattern: p.source, Text: file, Start: 0, End: len(file)}
+		}
+	}
+	return nil
+}
+
+// compilePatterns compiles each source, appending a ConfigError for every source which fails to compile
+func compilePatterns(sources []string, pointer string, compile func(string) (*regexp.Regexp, error), problems *ConfigErrors) []pattern {
+	patterns := make([]pattern, 0, len(sources))
+	for i, source := range sources {
+		re, err := compile(source)
+		if err != nil {
+			*problems = append(*problems, ConfigError{Path: fmt.Sprintf("%s/%d", pointer, i), Message: err.Error()})
+			continue
+		}
+		patterns = append(patterns, pattern{source: source, re: re})
+	}
+	return patterns
+}
+
+// compileRegexp compiles a regular expression, identifying the source in any error
+func compileRegexp(source string) (*regexp.Regexp, error) {
+	re, err := regexp.Compile(source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regular expression %q: %w", source, err)
+	}
+	return re, nil
+}
+
+// firstMatch returns the first pattern matching value, or nil if none match
+func firstMatch(patterns []pattern, value string) *pattern {
+	for i := range patterns {
+		if patterns[i].re.MatchString(value) {
+			return &patterns[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package model
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRuleSet_reportsAllInvalidPatterns(t *testing.T) {
+	r, err := NewRuleSet(map[string]Label{
+		"bug": {
+			Include:  []string{`(unclosed`, `bug`},
+			Exclude:  []string{`[`},
+			Branches: []string{`main`},
+		},
+		"docs": {
+			Files: &FileRule{Include: []string{"docs/[a"}, Exclude: []string{"**/*.png"}},
+			Color: "blue",
+		},
+		"question": {
+			Include: []string{`\bquestion\b`},
+			Color:   "#D876E3",
+		},
+	})
+
+	var problems ConfigErrors
+	if !errors.As(err, &problems) {
+		t.Fatalf("expected ConfigErrors, got %v", err)
+	}
+	paths := make([]string, 0, len(problems))
+	for _, problem := range problems {
+		paths = append(paths, problem.Path)
+	}
+	assert.Equal(t, []string{"/labels/bug/include/0", "/labels/bug/exclude/0", "/labels/docs/color", "/labels/docs/files/include/0"}, paths)
+
+	// the valid patterns remain usable
+	assert.NotNil(t, r)
+	assert.Equal(t, []string{"bug", "docs", "question"}, r.Names())
+	assert.Contains(t, r.LabelsFor("a bug"), "bug")
+}
+
+func TestRuleSet_LabelsFor(t *testing.T) {
+	r, err := NewRuleSet(map[string]Label{
+		"bug":         {Include: []string{`\bbug[s]?\b`}},
+		"help wanted": {Include: []string{`\bhelp( me)?\b`}, Exclude: []string{`\[test(ing)?\]`}},
+		"enhancement": {Include: []string{`\bfeat\b`}},
+	})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		input    []string
+		expected []string
+	}{
+		{"single match", []string{"a bug"}, []string{"bug"}},
+		{"multiple matches", []string{"feat: help with bugs"}, []string{"bug", "enhancement", "help wanted"}},
+		{"excluded label does not prevent others", []string{"[testing] help with a bug"}, []string{"bug"}},
+		{"no match", []string{"nothing"}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := r.LabelsFor(tt.input...)
+			gotKeys := make([]string, 0)
+			for key := range got {
+				gotKeys = append(gotKeys, key)
+			}
+			assert.ElementsMatch(t, tt.expected, gotKeys)
+		})
+	}
+}
+
+func TestRuleSet_LabelsForFiles(t *testing.T) {
+	r, err := NewRuleSet(map[string]Label{
+		"area/docs": {
+			Files: &FileRule{
+				Include: []string{"docs/**", "**/*.md"},
+				Exclude: []string{"CHANGELOG.md"},
+			},
+		},
+		"area/cli": {
+			Files: &FileRule{Include: []string{"cmd/**"}},
+		},
+		"bug": {
+			Include: []string{`\bbug\b`},
+		},
+	})
+	assert.NoError(t, err)
+	assert.True(t, r.HasFileRules())
+
+	tests := []struct {
+		name     string
+		files    []string
+		expected []string
+	}{
+		{"docs directory", []string{"docs/samples/full.yml"}, []string{"area/docs"}},
+		{"markdown anywhere", []string{"model/README.md"}, []string{"area/docs"}},
+		{"excluded file only", []string{"CHANGELOG.md"}, []string{}},
+		{"excluded file with included file", []string{"CHANGELOG.md", "README.md"}, []string{"area/docs"}},
+		{"multiple labels", []string{"cmd/labeler/root.go", "docs/art/avatar.svg"}, []string{"area/cli", "area/docs"}},
+		{"no match", []string{"labeler.go"}, []string{}},
+		{"no files", []string{}, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := r.LabelsForFiles(tt.files...)
+			gotKeys := make([]string, 0)
+			for key := range got {
+				gotKeys = append(gotKeys, key)
+			}
+			assert.ElementsMatch(t, tt.expected, gotKeys)
+		})
+	}
+}
+
+func TestRule_BranchAllowed(t *testing.T) {
+	r, err := NewRuleSet(map[string]Label{
+		"bug":    {Include: []string{`bug`}, Branches: []string{`main`, `feature/.+`}},
+		"always": {Include: []string{`always`}},
+	})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		rule   *Rule
+		branch string
+		want   bool
+	}{
+		{"unrestricted rule without branch", r.Rule("always"), "", true},
+		{"unrestricted rule with branch", r.Rule("always"), "develop", true},
+		{"nil rule", r.Rule("missing"), "develop", true},
+		{"restricted rule matching branch", r.Rule("bug"), "main", true},
+		{"restricted rule matching branch regex", r.Rule("bug"), "feature/yes", true},
+		{"restricted rule mismatched branch", r.Rule("bug"), "develop", false},
+		{"restricted rule without branch", r.Rule("bug"), "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.rule.BranchAllowed(tt.branch))
+		})
+	}
+}
+
+func TestRule_HeadBranchAllowed(t *testing.T) {
+	rules, err := NewRuleSet(map[string]Label{
+		"bug":     {HeadBranches: []string{`^fix/`}},
+		"release": {Include: []string{`\brelease\b`}, HeadBranches: []string{`^release/v\d+`}},
+		"docs":    {Include: []string{`\bdocs\b`}},
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"bug"}, sortedLabelNames(rules.LabelsForHeadBranch("fix/crash")))
+	assert.Empty(t, rules.LabelsForHeadBranch("release/v1"), "rules with include patterns aren't applied by head branch alone")
+	assert.Empty(t, rules.LabelsForHeadBranch(""))
+
+	assert.True(t, rules.Rule("release").HeadBranchAllowed("release/v1"))
+	assert.False(t, rules.Rule("release").HeadBranchAllowed("main"))
+	assert.False(t, rules.Rule("release").HeadBranchAllowed(""), "restricted rules require a head branch")
+	assert.True(t, rules.Rule("docs").HeadBranchAllowed(""))
+	assert.True(t, rules.Rule("missing").HeadBranchAllowed("main"))
+
+	assert.Equal(t, &Match{Field: "head_branch", Pattern: `^release/v\d+`, Text: "release/v1", Start: 0, End: 10},
+		rules.Rule("release").MatchHeadBranch("release/v1.2"))
+}
@@ -1,14 +1,19 @@
 package model
 
 import (
-	"regexp"
-	"strings"
+	"errors"
 
-	"gopkg.in/yaml.v2"
+	"gopkg.in/yaml.v3"
 )
 
 // SimpleConfig is the simplest supported config structure. See FullConfig for more functionality.
 type SimpleConfig struct {
+	// Kind optionally declares the schema of the config, which is otherwise detected from its structure: simple
+	Kind string `yaml:"kind,omitempty" json:"kind,omitempty"`
+
+	// Enable optionally disables labeling of issues or pull requests. Both are enabled when omitted.
+	Enable *Enable `yaml:"enable,omitempty" json:"enable,omitempty"`
+
 	// Comment will be applied to any issue or pull request matching the target labels
 	Comment string `yaml:"comment,omitempty" json:"comment,omitempty"`
 
@@ -17,32 +22,118 @@ type SimpleConfig struct {
 
 	// Branches are keyed by the label name, and valued by the array of branch names to match before applying
 	Branches map[string][]string `yaml:"branches,omitempty,flow" json:"branches,omitempty"`
+
+	rules    *RuleSet
+	template *CommentTemplate
 }
 
-// FromBytes parses the bytes into the SimpleConfig object
+// FromBytes parses the bytes into the SimpleConfig object. All label and branch patterns are compiled, and every
+// problem, from YAML type errors to invalid patterns, is reported together as ConfigErrors.
 func (s *SimpleConfig) FromBytes(b []byte) error {
-	return yaml.Unmarshal(b, &s)
+	var problems ConfigErrors
+	if err := yaml.Unmarshal(b, s); err != nil {
+		problems = decodeErrors(b, err)
+	}
+	if problem := kindProblem(s.Kind, KindSimple); problem != "" {
+		problems = append(problems, ConfigError{Path: "/kind", Message: problem})
+	}
+
+	rules, err := NewRuleSet(s.asLabels())
+	s.rules = rules
+	var ruleProblems ConfigErrors
+	if err != nil && !errors.As(err, &ruleProblems) {
+		return err
+	}
+	for i := range ruleProblems {
+		ruleProblems[i].Path = simplePointer(ruleProblems[i].Path)
+	}
+	problems = append(problems, ruleProblems...)
+
+	s.template = compileComment(&s.Comment, "/comment", &problems)
+	if len(problems) > 0 {
+		return locateConfigErrors(b, problems)
+	}
+	return nil
 }
 
 // LabelsFor allows config implementations to determine the labels to be applied to the input strings
 func (s *SimpleConfig) LabelsFor(text ...string) map[string]Label {
-	searchable := []byte(strings.Join(text, " "))
+	fields := make([]Field, 0, len(text))
+	for _, t := range text {
+		fields = append(fields, Field{Text: t})
+	}
+
 	labels := make(map[string]Label)
-	for key, patterns := range s.Labels {
-		var include []string
-		for _, pattern := range patterns {
-			re := regexp.MustCompile(pattern)
-			if re.Match(searchable) {
-				include = append(include, pattern)
-			}
+	for _, explanation := range s.Rules().Explain(fields...) {
+		if !explanation.Applied() {
+			continue
+		}
+		include := make([]string, 0, len(explanation.Matches))
+		for _, m := range explanation.Matches {
+			include = append(include, m.Pattern)
 		}
-		if len(include) > 0 {
-			labels[key] = Label{
-				Include:  include,
-				Exclude:  []string{},
-				Branches: s.Branches[key],
-			}
+		labels[explanation.Label] = Label{
+			Include:  include,
+			Exclude:  []string{},
+			Branches: s.Branches[explanation.Label],
 		}
 	}
 	return labels
 }
+
+// Explain evaluates every label against the named fields, describing which patterns matched each label
+func (s *SimpleConfig) Explain(fields ...Field) []Explanation {
+	return s.Rules().Explain(fields...)
+}
+
+// Rules returns the compiled rules of this config. Rules are compiled by FromBytes; a config constructed in code is
+// compiled on first use, omitting any invalid patterns.
+func (s *SimpleConfig) Rules() *RuleSet {
+	if s.rules == nil {
+		s.rules, _ = NewRuleSet(s.asLabels())
+	}
+	return s.rules
+}
+
+// IssueComment returns the comment template, which applies to both issues and pull requests, or nil if there is none
+func (s *SimpleConfig) IssueComment() *CommentTemplate {
+	if s.template == nil {
+		s.template = compileComment(&s.Comment, "/comment", nil)
+	}
+	return s.template
+}
+
+// CommentStrategy returns CommentStrategyCreate, as the simple schema always adds a new comment
+func (s *SimpleConfig) CommentStrategy() CommentStrategy {
+	return CommentStrategyCreate
+}
+
+// PullRequestComment returns the comment template, which applies to both issues and pull requests, or nil if there is none
+func (s *SimpleConfig) PullRequestComment() *CommentTemplate {
+	return s.IssueComment()
+}
+
+// asLabels converts the simple structure into the Label rules used by FullConfig
+func (s *SimpleConfig) asLabels() map[string]Label {
+	labels := make(map[string]Label, len(s.Labels))
+	for name, patterns := range s.Labels {
+		labels[name] = Label{Include: patterns, Branches: s.Branches[name]}
+	}
+	return labels
+}
+
+// simplePointer maps a pointer into FullConfig's structure (see NewRuleSet) to the equivalent SimpleConfig location
+func simplePointer(pointer string) string {
+	segments := splitPointer(pointer)
+	if len(segments) != 4 || segments[0] != "labels" {
+		return pointer
+	}
+	name := escapePointer(segments[1])
+	switch segments[2] {
+	case "include":
+		return "/labels/" + name + "/" + segments[3]
+	case "branches":
+		return "/branches/" + name + "/" + segments[3]
+	}
+	return pointer
+}
@@ -1,13 +1,16 @@
 package model
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 )
 
 func TestSimpleConfig_FromBytes(t *testing.T) {
+	bfalse := false
 	type fields struct {
+		Enable  *Enable
 		Comment string
 		Labels  map[string][]string
 	}
@@ -46,6 +49,14 @@ func TestSimpleConfig_FromBytes(t *testing.T) {
 			args{helperTestData(t, "simple_config_labels.yaml")},
 			false,
 		},
+		{"simple config enable",
+			fields{
+				Enable:  Enable{Issues: &bfalse}.Ptr(),
+				Comment: "Thanks for this!",
+				Labels:  map[string][]string{"bug": {"\\bbug[s]?\\b"}}},
+			args{helperTestData(t, "simple_config_enable.yaml")},
+			false,
+		},
 		{"simple config basic with invalid yaml should fail",
 			fields{},
 			args{[]byte("asf")},
@@ -66,6 +77,7 @@ func TestSimpleConfig_FromBytes(t *testing.T) {
 					t.Errorf("fromString() error = %v, wantErr %v", err, tt.wantErr)
 				}
 			} else {
+				assert.Equal(t, s.Enable, tt.fields.Enable)
 				assert.Equal(t, s.Comment, tt.fields.Comment)
 				assert.Equal(t, s.Labels, tt.fields.Labels)
 			}
@@ -217,3 +229,25 @@ func TestSimpleConfig_LabelsFor_withBranches(t *testing.T) {
 		})
 	}
 }
+
+func TestSimpleConfig_FromBytes_invalidPatterns(t *testing.T) {
+	s := &SimpleConfig{}
+	err := s.FromBytes([]byte(`labels:
+  'bug':
+    - 'bug'
+    - '(unclosed'
+branches:
+  'bug':
+    - '[main'
+`))
+
+	var problems ConfigErrors
+	if !errors.As(err, &problems) {
+		t.Fatalf("expected ConfigErrors, got %v", err)
+	}
+	assert.Len(t, problems, 2)
+	assert.Equal(t, "/labels/bug/1", problems[0].Path)
+	assert.Equal(t, 4, problems[0].Line)
+	assert.Equal(t, "/branches/bug/0", problems[1].Path)
+	assert.Equal(t, 7, problems[1].Line)
+}
@@ -0,0 +1,116 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+)
+
+// defaultSizeThresholds are applied when a size section doesn't define its own thresholds
+var defaultSizeThresholds = map[string]int{
+	"size/XS":  0,
+	"size/S":   10,
+	"size/M":   30,
+	"size/L":   100,
+	"size/XL":  500,
+	"size/XXL": 1000,
+}
+
+type (
+	// Size labels pull requests by the number of changed lines (additions plus deletions)
+	Size struct {
+		// Thresholds are keyed by label, and valued by the minimum number of changed lines for the label to apply.
+		// Defaults to size/XS (0), size/S (10), size/M (30), size/L (100), size/XL (500) and size/XXL (1000).
+		Thresholds map[string]int `yaml:"thresholds,omitempty" json:"thresholds,omitempty"`
+		// Exclude are globs of files whose changes aren't counted, such as lockfiles or generated code
+		Exclude []string `yaml:"exclude,omitempty,flow" json:"exclude,omitempty"`
+
+		excluded []pattern
+		compiled bool
+	}
+
+	// sizeThreshold is a single threshold, for ordering thresholds by size
+	sizeThreshold struct {
+		label string
+		lines int
+	}
+)
+
+// Labels returns every size label, from smallest to largest threshold
+func (s *Size) Labels() []string {
+	thresholds := s.thresholds()
+	labels := make([]string, 0, len(thresholds))
+	for _, t := range thresholds {
+		labels = append(labels, t.label)
+	}
+	return labels
+}
+
+// Label returns the label of the largest threshold met by the number of changed lines, and a match describing it.
+// The label is empty if no threshold is met.
+func (s *Size) Label(lines int) (string, *Match) {
+	thresholds := s.thresholds()
+	for i := len(thresholds) - 1; i >= 0; i-- {
+		if lines >= thresholds[i].lines {
+			text := fmt.Sprintf("%d changed lines", lines)
+			return thresholds[i].label, &Match{
+				Field:   "size",
+				Pattern: fmt.Sprintf(">= %d changed lines", thresholds[i].lines),
+				Text:    text,
+				Start:   0,
+				End:     len(text),
+			}
+		}
+	}
+	return "", nil
+}
+
+// HasExclusions returns true if any file is excluded, in which case changed lines must be counted per file
+func (s *Size) HasExclusions() bool {
+	return len(s.Exclude) > 0
+}
+
+// Excluded returns true if changes to the file aren't counted. Globs are compiled by FullConfig.FromBytes; a Size
+// constructed in code is compiled on first use, omitting any invalid globs.
+func (s *Size) Excluded(file string) bool {
+	if !s.compiled {
+		s.compile(nil)
+	}
+	return firstMatch(s.excluded, file) != nil
+}
+
+// compile compiles the exclusion globs and checks the thresholds, appending a ConfigError for each problem
+func (s *Size) compile(problems *ConfigErrors) {
+	var found ConfigErrors
+	s.excluded = compilePatterns(s.Exclude, "/size/exclude", globToRegexp, &found)
+	for _, t := range s.thresholds() {
+		if t.lines < 0 {
+			found = append(found, ConfigError{
+				Path:    "/size/thresholds/" + escapePointer(t.label),
+				Message: fmt.Sprintf("invalid threshold %d: expected a number of lines of at least 0", t.lines),
+			})
+		}
+	}
+	s.compiled = true
+	if problems != nil {
+		*problems = append(*problems, found...)
+	}
+}
+
+// thresholds returns the configured (or default) thresholds, from smallest to largest
+func (s *Size) thresholds() []sizeThreshold {
+	configured := s.Thresholds
+	if len(configured) == 0 {
+		configured = defaultSizeThresholds
+	}
+	thresholds := make([]sizeThreshold, 0, len(configured))
+	for label, lines := range configured {
+		thresholds = append(thresholds, sizeThreshold{label: label, lines: lines})
+	}
+	sort.Slice(thresholds, func(i, j int) bool {
+		if thresholds[i].lines != thresholds[j].lines {
+			return thresholds[i].lines < thresholds[j].lines
+		}
+		return thresholds[i].label < thresholds[j].label
+	})
+	return thresholds
+}
@@ -0,0 +1,70 @@
+package model
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSize_Label(t *testing.T) {
+	defaults := &Size{}
+	custom := &Size{Thresholds: map[string]int{"small": 0, "large": 200}}
+	tests := []struct {
+		name  string
+		size  *Size
+		lines int
+		want  string
+	}{
+		{name: "default smallest", size: defaults, lines: 0, want: "size/XS"},
+		{name: "default at threshold", size: defaults, lines: 30, want: "size/M"},
+		{name: "default below threshold", size: defaults, lines: 999, want: "size/XL"},
+		{name: "default largest", size: defaults, lines: 5000, want: "size/XXL"},
+		{name: "custom", size: custom, lines: 199, want: "small"},
+		{name: "custom largest", size: custom, lines: 200, want: "large"},
+		{name: "no threshold met", size: &Size{Thresholds: map[string]int{"large": 200}}, lines: 10, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, match := tt.size.Label(tt.lines)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.want == "", match == nil)
+		})
+	}
+
+	assert.Equal(t, []string{"size/XS", "size/S", "size/M", "size/L", "size/XL", "size/XXL"}, defaults.Labels())
+	_, match := defaults.Label(42)
+	assert.Equal(t, "size[0:16] \"42 changed lines\" matched `>= 30 changed lines`", match.String())
+}
+
+func TestFullConfig_FromBytes_size(t *testing.T) {
+	f := &FullConfig{}
+	assert.NoError(t, f.FromBytes([]byte(`size:
+  exclude: ['**/*.lock', 'go.sum']
+labels:
+  'bug':
+    include: ['bug']
+`)))
+	assert.True(t, f.Size.HasExclusions())
+	assert.True(t, f.Size.Excluded("web/yarn.lock"))
+	assert.True(t, f.Size.Excluded("go.sum"))
+	assert.False(t, f.Size.Excluded("main.go"))
+
+	f = &FullConfig{}
+	err := f.FromBytes([]byte(`size:
+  thresholds:
+    size/S: -1
+  exclude: ['[a']
+labels:
+  'bug':
+    include: ['bug']
+`))
+	var problems ConfigErrors
+	if !errors.As(err, &problems) {
+		t.Fatalf("expected ConfigErrors, got %v", err)
+	}
+	assert.Equal(t, ConfigErrors{
+		{Path: "/size/exclude/0", Line: 4, Column: 13, Message: `invalid glob "[a": unterminated character class`},
+		{Path: "/size/thresholds/size~1S", Line: 3, Column: 5, Message: "invalid threshold -1: expected a number of lines of at least 0"},
+	}, problems)
+}
@@ -0,0 +1,490 @@
+package model
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed schema/*.json
+var schemaFS embed.FS
+
+const schemaBaseURL = "https://raw.githubusercontent.com/jimschubert/labeler/HEAD/model/schema/"
+
+var yamlLinePattern = regexp.MustCompile(`line (\d+)`)
+
+// yamlTypeErrorPattern matches each of the errors of a yaml.TypeError, e.g. line 3: cannot unmarshal !!str into []string
+var yamlTypeErrorPattern = regexp.MustCompile(`^line (\d+): (.*)$`)
+
+// yamlTypeNames describes the Go types named by yaml.TypeError in terms of the config
+var yamlTypeNames = strings.NewReplacer(
+	"model.FullConfig", "a full config",
+	"model.SimpleConfig", "a simple config",
+	"map[string]model.Label", "a map of label rules",
+	"map[string][]string", "a map of pattern lists",
+	"model.Label", "a label rule",
+	"[]string", "a list of strings",
+	"*model.", "",
+	"model.", "",
+)
+
+// ConfigError describes a single problem found in a labeler configuration
+type ConfigError struct {
+	// File names the config the problem was found in, such as an extended config, or is empty if unknown
+	File string
+	// Path is the JSON pointer to the offending value, e.g. /labels/bug/include/0
+	Path string
+	// Line is the 1-based line of the offending value, or 0 if unknown
+	Line int
+	// Column is the 1-based column of the offending value, or 0 if unknown
+	Column int
+	// Message describes the problem
+	Message string
+}
+
+// Error formats the problem with its location
+func (e ConfigError) Error() string {
+	var sb strings.Builder
+	if e.Line > 0 {
+		sb.WriteString(fmt.Sprintf("line %d, column %d: ", e.Line, e.Column))
+	}
+	if e.Path != "" {
+		sb.WriteString(e.Path + ": ")
+	}
+	sb.WriteString(e.Message)
+	return sb.String()
+}
+
+// ConfigErrors is an aggregate of all problems found in a labeler configuration
+type ConfigErrors []ConfigError
+
+// Error formats each problem on its own line
+func (e ConfigErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, problem := range e {
+		messages = append(messages, problem.Error())
+	}
+	return strings.Join(messages, "\n")
+}
+
+// Validate checks config bytes against the embedded JSON schemas and compiles every regular expression.
+// The returned error is of type ConfigErrors, and reports each problem with its line and column.
+func Validate(b []byte) (kind string, err error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return "", ConfigErrors{yamlSyntaxError(err)}
+	}
+	if len(doc.Content) == 0 {
+		return "", ConfigErrors{{Line: 1, Column: 1, Message: "config is empty"}}
+	}
+	root := doc.Content[0]
+
+	kind = configKind(root)
+	schema, err := compileSchema(kind)
+	if err != nil {
+		return kind, err
+	}
+
+	instance, err := toJSONValue(root)
+	if err != nil {
+		return kind, ConfigErrors{{Line: root.Line, Column: root.Column, Message: err.Error()}}
+	}
+
+	var problems ConfigErrors
+	if err := schema.Validate(instance); err != nil {
+		var validationError *jsonschema.ValidationError
+		if !errors.As(err, &validationError) {
+			return kind, err
+		}
+		for _, leaf := range validationLeaves(validationError) {
+			problems = append(problems, newConfigError(root, leaf.InstanceLocation, leaf.Message))
+		}
+	}
+
+	for _, pointer := range patternPointers(kind, instance) {
+		pattern := pointerValue(instance, pointer)
+		if _, err := compileRegexp(pattern); err != nil {
+			problems = append(problems, newConfigError(root, pointer, err.Error()))
+		}
+	}
+
+	for _, pointer := range globPointers(kind, instance) {
+		pattern := pointerValue(instance, pointer)
+		if _, err := globToRegexp(pattern); err != nil {
+			problems = append(problems, newConfigError(root, pointer, err.Error()))
+		}
+	}
+
+	for _, pointer := range authorPointers(kind, instance) {
+		if err := checkAuthor(pointerValue(instance, pointer)); err != nil {
+			problems = append(problems, newConfigError(root, pointer, err.Error()))
+		}
+	}
+
+	for _, pointer := range commentPointers(kind, instance) {
+		if _, err := NewCommentTemplate(pointerValue(instance, pointer)); err != nil {
+			problems = append(problems, newConfigError(root, pointer, err.Error()))
+		}
+	}
+
+	if len(problems) > 0 {
+		sortConfigErrors(problems)
+		return kind, problems
+	}
+	return kind, nil
+}
+
+// locateConfigErrors resolves the line and column of each problem's Path within the YAML config bytes
+func locateConfigErrors(b []byte, problems ConfigErrors) ConfigErrors {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(b, &doc); err != nil || len(doc.Content) == 0 {
+		return problems
+	}
+	located := make(ConfigErrors, 0, len(problems))
+	for _, problem := range problems {
+		if problem.Line > 0 {
+			located = append(located, problem)
+			continue
+		}
+		located = append(located, newConfigError(doc.Content[0], problem.Path, problem.Message))
+	}
+	return located
+}
+
+// sortConfigErrors orders problems by their location
+func sortConfigErrors(problems ConfigErrors) {
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].Line != problems[j].Line {
+			return problems[i].Line < problems[j].Line
+		}
+		return problems[i].Column < problems[j].Column
+	})
+}
+
+// decodeErrors converts an error decoding a config into ConfigErrors: each type error of a yaml.TypeError is located at
+// the first node of its line, and any other error is treated as a syntax error.
+func decodeErrors(b []byte, err error) ConfigErrors {
+	var typeError *yaml.TypeError
+	if !errors.As(err, &typeError) {
+		return ConfigErrors{yamlSyntaxError(err)}
+	}
+	var doc yaml.Node
+	_ = yaml.Unmarshal(b, &doc)
+
+	problems := make(ConfigErrors, 0, len(typeError.Errors))
+	for _, message := range typeError.Errors {
+		problem := ConfigError{Message: message}
+		if m := yamlTypeErrorPattern.FindStringSubmatch(message); m != nil {
+			problem.Line, _ = strconv.Atoi(m[1])
+			problem.Column = max(columnOf(&doc, problem.Line), 1)
+			problem.Message = yamlTypeNames.Replace(m[2])
+		}
+		problems = append(problems, problem)
+	}
+	return problems
+}
+
+// columnOf returns the column of the first node on the line, or 0 if there is none
+func columnOf(node *yaml.Node, line int) int {
+	if node.Kind != yaml.DocumentNode && node.Line == line {
+		return node.Column
+	}
+	for _, child := range node.Content {
+		if column := columnOf(child, line); column > 0 {
+			return column
+		}
+	}
+	return 0
+}
+
+// configKind determines the kind of config, as declared by its kind key or else detected from its structure. A kind
+// key with an unknown value is ignored here, and reported once the config is validated or parsed.
+func configKind(root *yaml.Node) string {
+	if root.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			key, value := root.Content[i], root.Content[i+1]
+			if key.Value == "kind" && (value.Value == KindFull || value.Value == KindSimple) {
+				return value.Value
+			}
+		}
+	}
+	return detectKind(root)
+}
+
+// kindProblem describes the problem with the declared kind of a config parsed as the wanted kind, or returns an empty
+// string if there is none
+func kindProblem(declared, want string) string {
+	switch declared {
+	case "", want:
+		return ""
+	case KindFull, KindSimple:
+		return fmt.Sprintf("invalid kind %q: expected %s", declared, want)
+	}
+	return fmt.Sprintf("invalid kind %q: expected one of full, simple", declared)
+}
+
+// detectKind determines whether the root node looks like a "full" or "simple" config
+func detectKind(root *yaml.Node) string {
+	if root.Kind != yaml.MappingNode {
+		return KindFull
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		switch key.Value {
+		case "comment", "branches":
+			return KindSimple
+		case "labels":
+			if value.Kind == yaml.MappingNode && len(value.Content) > 1 && value.Content[1].Kind == yaml.SequenceNode {
+				return KindSimple
+			}
+		}
+	}
+	return KindFull
+}
+
+// compileSchema compiles the embedded schema for the given kind of config
+func compileSchema(kind string) (*jsonschema.Schema, error) {
+	c := jsonschema.NewCompiler()
+	for _, name := range []string{"labeler.schema.json", "labeler.full.schema.json", "labeler.simple.schema.json"} {
+		f, err := schemaFS.Open("schema/" + name)
+		if err != nil {
+			return nil, err
+		}
+		err = c.AddResource(schemaBaseURL+name, f)
+		_ = f.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c.Compile(fmt.Sprintf("%slabeler.%s.schema.json", schemaBaseURL, kind))
+}
+
+// toJSONValue converts a YAML node into the generic structure expected by the JSON schema validator
+func toJSONValue(node *yaml.Node) (interface{}, error) {
+	var v interface{}
+	if err := node.Decode(&v); err != nil {
+		return nil, err
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var result interface{}
+	if err := json.Unmarshal(b, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// validationLeaves flattens a validation error into its most specific causes
+func validationLeaves(err *jsonschema.ValidationError) []*jsonschema.ValidationError {
+	if len(err.Causes) == 0 {
+		return []*jsonschema.ValidationError{err}
+	}
+	var leaves []*jsonschema.ValidationError
+	for _, cause := range err.Causes {
+		leaves = append(leaves, validationLeaves(cause)...)
+	}
+	return leaves
+}
+
+// patternPointers lists the JSON pointers of every regular expression in the config
+func patternPointers(kind string, instance interface{}) []string {
+	var pointers []string
+	root, _ := instance.(map[string]interface{})
+	labels, _ := root["labels"].(map[string]interface{})
+	names := sortedKeys(labels)
+
+	if kind == "simple" {
+		for _, name := range names {
+			pointers = append(pointers, stringPointers("/labels/"+escapePointer(name), labels[name])...)
+		}
+		branches, _ := root["branches"].(map[string]interface{})
+		for _, name := range sortedKeys(branches) {
+			pointers = append(pointers, stringPointers("/branches/"+escapePointer(name), branches[name])...)
+		}
+		return pointers
+	}
+
+	for _, name := range names {
+		rule, _ := labels[name].(map[string]interface{})
+		for _, field := range []string{"include", "exclude", "branches", "headBranches"} {
+			pointers = append(pointers, stringPointers("/labels/"+escapePointer(name)+"/"+field, rule[field])...)
+		}
+	}
+	return pointers
+}
+
+// globPointers lists the JSON pointers of every file glob in the config
+func globPointers(kind string, instance interface{}) []string {
+	if kind == "simple" {
+		return nil
+	}
+	var pointers []string
+	root, _ := instance.(map[string]interface{})
+	labels, _ := root["labels"].(map[string]interface{})
+	for _, name := range sortedKeys(labels) {
+		rule, _ := labels[name].(map[string]interface{})
+		files, _ := rule["files"].(map[string]interface{})
+		for _, field := range []string{"include", "exclude"} {
+			pointers = append(pointers, stringPointers("/labels/"+escapePointer(name)+"/files/"+field, files[field])...)
+		}
+	}
+	size, _ := root["size"].(map[string]interface{})
+	pointers = append(pointers, stringPointers("/size/exclude", size["exclude"])...)
+	return pointers
+}
+
+// authorPointers lists the JSON pointers of every author entry in the config
+func authorPointers(kind string, instance interface{}) []string {
+	if kind == "simple" {
+		return nil
+	}
+	var pointers []string
+	root, _ := instance.(map[string]interface{})
+	labels, _ := root["labels"].(map[string]interface{})
+	for _, name := range sortedKeys(labels) {
+		rule, _ := labels[name].(map[string]interface{})
+		authors, _ := rule["authors"].(map[string]interface{})
+		for _, field := range []string{"include", "exclude"} {
+			pointers = append(pointers, stringPointers("/labels/"+escapePointer(name)+"/authors/"+field, authors[field])...)
+		}
+	}
+	return pointers
+}
+
+// commentPointers lists the JSON pointers of every comment template in the config
+func commentPointers(kind string, instance interface{}) []string {
+	root, _ := instance.(map[string]interface{})
+	if kind == "simple" {
+		if _, ok := root["comment"].(string); ok {
+			return []string{"/comment"}
+		}
+		return nil
+	}
+
+	var pointers []string
+	comments, _ := root["comments"].(map[string]interface{})
+	for _, field := range []string{"issues", "prs"} {
+		if _, ok := comments[field].(string); ok {
+			pointers = append(pointers, "/comments/"+field)
+		}
+	}
+	return pointers
+}
+
+// stringPointers returns pointers to each string item of value, if value is an array
+func stringPointers(prefix string, value interface{}) []string {
+	var pointers []string
+	items, _ := value.([]interface{})
+	for i, item := range items {
+		if _, ok := item.(string); ok {
+			pointers = append(pointers, fmt.Sprintf("%s/%d", prefix, i))
+		}
+	}
+	return pointers
+}
+
+// pointerValue resolves a JSON pointer known to reference a string
+func pointerValue(instance interface{}, pointer string) string {
+	current := instance
+	for _, segment := range splitPointer(pointer) {
+		switch v := current.(type) {
+		case map[string]interface{}:
+			current = v[segment]
+		case []interface{}:
+			i, _ := strconv.Atoi(segment)
+			current = v[i]
+		}
+	}
+	s, _ := current.(string)
+	return s
+}
+
+// newConfigError creates a ConfigError for the node referenced by the JSON pointer
+func newConfigError(root *yaml.Node, pointer string, message string) ConfigError {
+	problem := ConfigError{Path: pointer, Message: message}
+	if node := locate(root, pointer); node != nil {
+		problem.Line = node.Line
+		problem.Column = node.Column
+	}
+	return problem
+}
+
+// locate finds the YAML node referenced by a JSON pointer. Mapping entries resolve to their key node so that
+// reported positions point at the entry's name rather than its (possibly multi-line) value.
+func locate(root *yaml.Node, pointer string) *yaml.Node {
+	current := root
+	located := root
+	for _, segment := range splitPointer(pointer) {
+		if current == nil {
+			return located
+		}
+		switch current.Kind {
+		case yaml.MappingNode:
+			var next *yaml.Node
+			for i := 0; i+1 < len(current.Content); i += 2 {
+				if current.Content[i].Value == segment {
+					located = current.Content[i]
+					next = current.Content[i+1]
+					break
+				}
+			}
+			current = next
+		case yaml.SequenceNode:
+			i, err := strconv.Atoi(segment)
+			if err != nil || i < 0 || i >= len(current.Content) {
+				return located
+			}
+			current = current.Content[i]
+			located = current
+		default:
+			return located
+		}
+	}
+	return located
+}
+
+// splitPointer splits a JSON pointer into unescaped segments
+func splitPointer(pointer string) []string {
+	if pointer == "" || pointer == "/" {
+		return nil
+	}
+	segments := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+	for i, segment := range segments {
+		segments[i] = strings.ReplaceAll(strings.ReplaceAll(segment, "~1", "/"), "~0", "~")
+	}
+	return segments
+}
+
+// escapePointer escapes a single JSON pointer segment
+func escapePointer(segment string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(segment, "~", "~0"), "/", "~1")
+}
+
+// yamlSyntaxError converts a YAML parser error into a ConfigError, extracting the line where possible
+func yamlSyntaxError(err error) ConfigError {
+	problem := ConfigError{Message: err.Error()}
+	if m := yamlLinePattern.FindStringSubmatch(err.Error()); m != nil {
+		problem.Line, _ = strconv.Atoi(m[1])
+		problem.Column = 1
+	}
+	return problem
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
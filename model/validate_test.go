@@ -0,0 +1,244 @@
+package model
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		kind     string
+		problems []ConfigError
+	}{
+		{
+			name:  "valid full config",
+			input: string(helperTestData(t, "full_config.yaml")),
+			kind:  "full",
+		},
+		{
+			name:  "valid simple config",
+			input: string(helperTestData(t, "simple_config_labels.yaml")),
+			kind:  "simple",
+		},
+		{
+			name: "label only defining a color and description",
+			input: `conventional:
+  types:
+    feat: enhancement
+labels:
+  'enhancement':
+    color: 'a2eeef'
+    description: New feature or request
+`,
+			kind: "full",
+		},
+		{
+			name: "unknown label property",
+			input: `labels:
+  'bug':
+    include: ['bug']
+    unknown: true
+`,
+			kind: "full",
+			problems: []ConfigError{
+				{Path: "/labels/bug", Line: 2, Column: 3, Message: "additionalProperties 'unknown' not allowed"},
+			},
+		},
+		{
+			name: "invalid regular expressions",
+			input: `labels:
+  'bug':
+    include:
+      - 'bug'
+      - '(unclosed'
+    branches: ['[main']
+`,
+			kind: "full",
+			problems: []ConfigError{
+				{Path: "/labels/bug/include/1", Line: 5, Column: 9},
+				{Path: "/labels/bug/branches/0", Line: 6, Column: 16},
+			},
+		},
+		{
+			name: "invalid simple regular expression",
+			input: `comment: hi
+labels:
+  'bug':
+    - '(unclosed'
+`,
+			kind: "simple",
+			problems: []ConfigError{
+				{Path: "/labels/bug/0", Line: 4, Column: 7},
+			},
+		},
+		{
+			name: "invalid glob",
+			input: `labels:
+  'docs':
+    files:
+      include: ['docs/[a']
+`,
+			kind: "full",
+			problems: []ConfigError{
+				{Path: "/labels/docs/files/include/0", Line: 4, Column: 17},
+			},
+		},
+		{
+			name: "invalid label color",
+			input: `labels:
+  'bug':
+    include: ['bug']
+    color: 'red'
+    description: 'Something is not working'
+`,
+			kind: "full",
+			problems: []ConfigError{
+				{Path: "/labels/bug/color", Line: 4, Column: 5},
+			},
+		},
+		{
+			name: "invalid comment template",
+			input: `comments:
+  prs: 'Thanks {{ .Author }'
+labels:
+  'bug':
+    include: ['bug']
+`,
+			kind: "full",
+			problems: []ConfigError{
+				{Path: "/comments/prs", Line: 2, Column: 3},
+			},
+		},
+		{
+			name: "invalid comment strategy",
+			input: `comments:
+  issues: 'Thanks!'
+  strategy: sometimes
+labels:
+  'bug':
+    include: ['bug']
+`,
+			kind: "full",
+			problems: []ConfigError{
+				{Path: "/comments/strategy", Line: 3, Column: 3},
+			},
+		},
+		{
+			name: "invalid conventional label",
+			input: `conventional:
+  types:
+    feat: ''
+labels:
+  'bug':
+    include: ['bug']
+`,
+			kind: "full",
+			problems: []ConfigError{
+				{Path: "/conventional/types/feat", Line: 3, Column: 5},
+			},
+		},
+		{
+			name: "invalid size exclusion",
+			input: `size:
+  exclude: ['[a']
+labels:
+  'bug':
+    include: ['bug']
+`,
+			kind: "full",
+			problems: []ConfigError{
+				{Path: "/size/exclude/0", Line: 2, Column: 13},
+			},
+		},
+		{
+			name: "author rules",
+			input: `labels:
+  'dependencies':
+    authors:
+      include: ['dependabot[bot]']
+  'bug':
+    include: ['bug']
+    authors:
+      exclude: ['type:robot']
+`,
+			kind: "full",
+			problems: []ConfigError{
+				{Path: "/labels/bug/authors/exclude/0", Line: 8, Column: 17},
+			},
+		},
+		{
+			name: "invalid head branch",
+			input: `fields: [title, head_branch]
+labels:
+  'bug':
+    headBranches: ['^fix/(']
+`,
+			kind: "full",
+			problems: []ConfigError{
+				{Path: "/labels/bug/headBranches/0", Line: 4, Column: 20},
+			},
+		},
+		{
+			name: "extends without labels of its own",
+			input: `extends: my-org/.github/labeler/base.yml@v1
+labels:
+  'wontfix': ~
+`,
+			kind: "full",
+		},
+		{
+			name:  "invalid extends",
+			input: "extends: my-org\n",
+			kind:  "full",
+			problems: []ConfigError{
+				{Path: "/extends", Line: 1, Column: 1},
+			},
+		},
+		{
+			name:  "declared kind",
+			input: "kind: simple\nenable:\n  issues: false\nlabels:\n  'bug': ['bug']\n",
+			kind:  "simple",
+		},
+		{
+			name:  "unknown kind",
+			input: "kind: fancy\nlabels:\n  'bug':\n    include: ['bug']\n",
+			kind:  "full",
+			problems: []ConfigError{
+				{Path: "/kind", Line: 1, Column: 1},
+			},
+		},
+		{
+			name:     "yaml syntax error",
+			input:    "labels:\n  bug: [\n",
+			problems: []ConfigError{{Line: 2, Column: 1}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kind, err := Validate([]byte(tt.input))
+			assert.Equal(t, tt.kind, kind)
+			if len(tt.problems) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+
+			var problems ConfigErrors
+			if !errors.As(err, &problems) {
+				t.Fatalf("expected ConfigErrors, got %v", err)
+			}
+			assert.Len(t, problems, len(tt.problems))
+			for i, want := range tt.problems {
+				assert.Equal(t, want.Path, problems[i].Path)
+				assert.Equal(t, want.Line, problems[i].Line)
+				assert.Equal(t, want.Column, problems[i].Column)
+				if want.Message != "" {
+					assert.Equal(t, want.Message, problems[i].Message)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package labeler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// rateLimiter tracks GitHub's rate limit response headers, allowing long-running operations to pause before the
+// rate limit is exhausted rather than failing once it has been.
+type rateLimiter struct {
+	mu         sync.Mutex
+	remaining  int
+	reset      time.Time
+	retryAfter time.Time
+
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{remaining: -1, now: time.Now, sleep: sleepContext}
+}
+
+// transport wraps base, observing the rate limit headers of every response
+func (r *rateLimiter) transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		resp, err := base.RoundTrip(req)
+		if err == nil {
+			r.observe(resp)
+		}
+		return resp, err
+	})
+}
+
+// observe records the primary rate limit (X-RateLimit-Remaining, X-RateLimit-Reset) and any secondary rate limit
+// (Retry-After) reported by the response
+func (r *rateLimiter) observe(resp *http.Response) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if remaining, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining")); err == nil {
+		r.remaining = remaining
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			r.reset = time.Unix(reset, 0)
+		}
+	}
+
+	if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			r.retryAfter = r.now().Add(time.Duration(seconds) * time.Second)
+		}
+	}
+}
+
+// wait blocks while a secondary rate limit is in effect, or while no more than reserve requests remain before the
+// primary rate limit resets. A nil rateLimiter never waits.
+func (r *rateLimiter) wait(ctx context.Context, reserve int) error {
+	if r == nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	now := r.now()
+	var until time.Time
+	if r.retryAfter.After(now) {
+		until = r.retryAfter
+	}
+	if r.remaining >= 0 && r.remaining <= reserve && r.reset.After(now) && r.reset.After(until) {
+		// allow a moment beyond the reset to account for clock skew
+		until = r.reset.Add(time.Second)
+	}
+	r.mu.Unlock()
+
+	if until.IsZero() {
+		return nil
+	}
+
+	d := until.Sub(now)
+	log.Warnf("Approaching GitHub rate limit, waiting %s", d.Round(time.Second))
+	if err := r.sleep(ctx, d); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.reset.After(r.now()) {
+		// the limit has reset; the next response reports the actual remaining count
+		r.remaining = -1
+	}
+	return nil
+}
+
+// roundTripperFunc adapts a function to http.RoundTripper
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip implements http.RoundTripper
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// sleepContext waits for the duration, or until the context is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
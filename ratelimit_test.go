@@ -0,0 +1,117 @@
+package labeler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter_transport_observes_headers(t *testing.T) {
+	reset := time.Now().Add(time.Hour).Unix()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "42")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset, 10))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	limiter := newRateLimiter()
+	client := &http.Client{Transport: limiter.transport(nil)}
+	resp, err := client.Get(server.URL)
+	assert.NoError(t, err)
+	_ = resp.Body.Close()
+
+	assert.Equal(t, 42, limiter.remaining)
+	assert.Equal(t, reset, limiter.reset.Unix())
+}
+
+func TestRateLimiter_wait(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	tests := []struct {
+		name    string
+		resp    *http.Response
+		reserve int
+		want    time.Duration
+	}{
+		{
+			name:    "unknown limit",
+			resp:    &http.Response{StatusCode: http.StatusOK, Header: http.Header{}},
+			reserve: 10,
+			want:    0,
+		},
+		{
+			name:    "above reserve",
+			resp:    rateLimitResponse(http.StatusOK, "11", now.Add(time.Minute), ""),
+			reserve: 10,
+			want:    0,
+		},
+		{
+			name:    "at reserve",
+			resp:    rateLimitResponse(http.StatusOK, "10", now.Add(time.Minute), ""),
+			reserve: 10,
+			want:    time.Minute + time.Second,
+		},
+		{
+			name:    "exhausted but already reset",
+			resp:    rateLimitResponse(http.StatusOK, "0", now.Add(-time.Minute), ""),
+			reserve: 10,
+			want:    0,
+		},
+		{
+			name:    "secondary rate limit",
+			resp:    rateLimitResponse(http.StatusForbidden, "100", now.Add(time.Hour), "30"),
+			reserve: 10,
+			want:    30 * time.Second,
+		},
+		{
+			name:    "secondary rate limit with exhausted primary",
+			resp:    rateLimitResponse(http.StatusTooManyRequests, "0", now.Add(time.Hour), "30"),
+			reserve: 10,
+			want:    time.Hour + time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var slept time.Duration
+			limiter := newRateLimiter()
+			limiter.now = func() time.Time { return now }
+			limiter.sleep = func(_ context.Context, d time.Duration) error {
+				slept = d
+				return nil
+			}
+
+			limiter.observe(tt.resp)
+			assert.NoError(t, limiter.wait(context.Background(), tt.reserve))
+			assert.Equal(t, tt.want, slept)
+		})
+	}
+}
+
+func TestRateLimiter_wait_nil(t *testing.T) {
+	var limiter *rateLimiter
+	assert.NoError(t, limiter.wait(context.Background(), 10))
+}
+
+func TestRateLimiter_wait_cancelled(t *testing.T) {
+	limiter := newRateLimiter()
+	limiter.observe(rateLimitResponse(http.StatusOK, "0", time.Now().Add(time.Hour), ""))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, limiter.wait(ctx, 0), context.Canceled)
+}
+
+func rateLimitResponse(status int, remaining string, reset time.Time, retryAfter string) *http.Response {
+	header := http.Header{}
+	header.Set("X-RateLimit-Remaining", remaining)
+	header.Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+	if retryAfter != "" {
+		header.Set("Retry-After", retryAfter)
+	}
+	return &http.Response{StatusCode: status, Header: header}
+}
@@ -0,0 +1,44 @@
+package labeler
+
+import (
+	"github.com/google/go-github/v50/github"
+	"github.com/jimschubert/labeler/model"
+)
+
+type (
+	// Result describes the outcome of labeling an issue or pull request. In dry-run mode, it describes the changes
+	// which would have been made.
+	Result struct {
+		// Event is the event which was processed, e.g. issues or pull_request
+		Event string `json:"event"`
+		// Number is the number of the issue or pull request
+		Number int `json:"number"`
+		// Evaluated are the labels which matched the issue or pull request, or were named by a slash command. Each is
+		// either added, skipped, or removed by a slash command.
+		Evaluated []string `json:"evaluated"`
+		// Matches describe the patterns which matched each evaluated label, keyed by label. Matches are only explained
+		// in dry-run mode, when running on GitHub Actions, or when a comment is configured.
+		Matches map[string][]model.Match `json:"matches,omitempty"`
+		// Added are the labels which were added
+		Added []string `json:"added"`
+		// Skipped are the labels which were evaluated but not added, and why
+		Skipped []SkippedLabel `json:"skipped"`
+		// Removed are the labels which were removed, either by sync mode, a size change or a slash command
+		Removed []string `json:"removed"`
+		// Comment is the comment which was created or updated, or nil if there was none
+		Comment *github.IssueComment `json:"comment,omitempty"`
+	}
+
+	// SkippedLabel is a label which was evaluated but not added
+	SkippedLabel struct {
+		// Name of the label
+		Name string `json:"name"`
+		// Reason the label wasn't added, e.g. already applied
+		Reason string `json:"reason"`
+	}
+)
+
+// skip records a label which was evaluated but not added
+func (r *Result) skip(name, reason string) {
+	r.Skipped = append(r.Skipped, SkippedLabel{Name: name, Reason: reason})
+}
@@ -0,0 +1,337 @@
+package labeler
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/google/go-github/v50/github"
+	"github.com/jimschubert/labeler/model"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+)
+
+// maxPayloadSize is the largest webhook payload GitHub delivers (25 MB)
+const maxPayloadSize = 25 << 20
+
+var (
+	// issueActions are the issues webhook actions which result in labeling
+	issueActions = []string{"opened", "edited", "reopened"}
+	// pullRequestActions are the pull_request webhook actions which result in labeling
+	pullRequestActions = []string{"opened", "edited", "reopened", "synchronize"}
+	// issueCommentActions are the issue_comment webhook actions whose slash commands are run
+	issueCommentActions = []string{"created"}
+)
+
+// Server receives GitHub webhooks, labeling issues and pull requests as their events are delivered.
+// It implements http.Handler.
+type Server struct {
+	secret     []byte
+	configPath string
+	labelOpts  []OptFn
+	configs    *configCache
+	newLabeler func(opts ...OptFn) (*Labeler, error)
+
+	app           *appCredentials
+	mu            sync.Mutex
+	installations map[int64]oauth2.TokenSource
+}
+
+// maxCachedConfigs bounds the configs retained by a configCache, as every base commit of a pull request is cached
+const maxCachedConfigs = 1000
+
+// configCache retains the parsed config of each repository and ref, so the config isn't downloaded for every delivery.
+// Once full, the config cached first is discarded.
+type configCache struct {
+	mu      sync.RWMutex
+	configs map[string]cachedConfig
+	order   []string
+}
+
+// cachedConfig is a config retained by a configCache, along with the configs it inherits from
+type cachedConfig struct {
+	config    model.Config
+	inherited []model.ConfigReference
+}
+
+// NewServer constructs a Server which verifies deliveries against the webhook secret. Options apply to the Labeler
+// constructed for every delivery; options identifying a single issue or pull request are taken from the delivery.
+// When authenticating as a GitHub App (see WithAppID), each delivery is processed as the installation which sent it.
+func NewServer(secret string, labelOpts ...OptFn) (*Server, error) {
+	if secret == "" {
+		return nil, errors.New("a webhook secret is required")
+	}
+
+	options := Opt{baseURL: os.Getenv("GITHUB_API_URL")}
+	for _, opt := range labelOpts {
+		opt(&options)
+	}
+	configPath := options.configPath
+	if configPath == "" {
+		configPath = model.DefaultConfigPath
+	}
+
+	var app *appCredentials
+	if options.appID != 0 {
+		if err := options.loadPrivateKey(); err != nil {
+			return nil, err
+		}
+		if _, err := parsePrivateKey(options.privateKey); err != nil {
+			return nil, err
+		}
+		app = &appCredentials{appID: options.appID, privateKey: options.privateKey, baseURL: options.baseURL, uploadURL: options.uploadURL}
+	}
+
+	return &Server{
+		secret:        []byte(secret),
+		configPath:    configPath,
+		labelOpts:     labelOpts,
+		configs:       newConfigCache(),
+		newLabeler:    NewWithOptions,
+		app:           app,
+		installations: make(map[int64]oauth2.TokenSource),
+	}, nil
+}
+
+// ServeHTTP verifies the X-Hub-Signature-256 header of a webhook delivery and dispatches its payload by event type
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	payload, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPayloadSize))
+	if err != nil {
+		http.Error(w, "unable to read payload", http.StatusBadRequest)
+		return
+	}
+
+	if !s.validSignature(r.Header.Get("X-Hub-Signature-256"), payload) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	eventType := github.WebHookType(r)
+	entry := log.WithFields(log.Fields{"event": eventType, "delivery": github.DeliveryID(r)})
+	event, err := github.ParseWebHook(eventType, payload)
+	if err != nil {
+		entry.WithFields(log.Fields{"err": err}).Warn("Unable to parse webhook.")
+		http.Error(w, "unable to parse payload", http.StatusBadRequest)
+		return
+	}
+
+	switch e := event.(type) {
+	case *github.PingEvent:
+		entry.Info("Received ping.")
+	case *github.PushEvent:
+		s.handlePush(e)
+	case *github.IssuesEvent:
+		if !slices.Contains(issueActions, e.GetAction()) {
+			break
+		}
+		err = s.label(r, issue, e.GetRepo(), e.GetInstallation(), e.GetIssue().GetNumber(), payload)
+	case *github.PullRequestEvent:
+		if !slices.Contains(pullRequestActions, e.GetAction()) {
+			break
+		}
+		err = s.label(r, pullRequest, e.GetRepo(), e.GetInstallation(), e.GetNumber(), payload)
+	case *github.IssueCommentEvent:
+		if !slices.Contains(issueCommentActions, e.GetAction()) {
+			break
+		}
+		err = s.label(r, issueComment, e.GetRepo(), e.GetInstallation(), e.GetIssue().GetNumber(), payload)
+	default:
+		entry.Debug("Ignoring unsupported event.")
+	}
+
+	if err != nil {
+		entry.WithFields(log.Fields{"err": err}).Error("Labeling failed.")
+		http.Error(w, "labeling failed", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// label constructs a Labeler for the delivery, which reads the issue or pull request from the payload
+func (s *Server) label(r *http.Request, event string, repo *github.Repository, installation *github.Installation, number int, payload []byte) error {
+	opts := append(s.labelOpts[:len(s.labelOpts):len(s.labelOpts)],
+		WithContext(r.Context()),
+		WithOwner(repo.GetOwner().GetLogin()),
+		WithRepo(repo.GetName()),
+		WithEvent(event),
+		WithID(number),
+		WithData(string(payload)),
+		// deliveries aren't steps of a workflow, even if the server runs within one
+		WithActions(false),
+		withConfigCache(s.configs),
+	)
+	if s.app != nil && installation.GetID() != 0 {
+		source, err := s.installationTokens(installation.GetID())
+		if err != nil {
+			return err
+		}
+		opts = append(opts, withTokenSource(source))
+	}
+
+	l, err := s.newLabeler(opts...)
+	if err != nil {
+		return fmt.Errorf("could not initialize labeler: %w", err)
+	}
+	log.Infof("Labeling %s #%d of %s", event, number, repo.GetFullName())
+	return l.Execute()
+}
+
+// handlePush discards the cached config of a repository when a push to a branch modifies the config: the config of the
+// default branch for a push to the default branch, and the config read at the branch otherwise. Configs read at a
+// commit SHA never change. Cached configs of other repositories which inherit a modified config, by extending it or
+// by falling back to it, are discarded too.
+func (s *Server) handlePush(e *github.PushEvent) {
+	repo := e.GetRepo()
+	branch, ok := strings.CutPrefix(e.GetRef(), "refs/heads/")
+	if !ok {
+		return
+	}
+	owner, name := repo.GetOwner().GetLogin(), repo.GetName()
+	isDefaultBranch := branch == repo.GetDefaultBranch()
+
+	commits := e.Commits
+	if e.HeadCommit != nil {
+		commits = append(commits, e.HeadCommit)
+	}
+	var files []string
+	for _, commit := range commits {
+		files = slices.Concat(files, commit.Added, commit.Modified, commit.Removed)
+	}
+	modifies := func(path string) bool {
+		return slices.ContainsFunc(files, func(file string) bool { return isConfigFile(path, file) })
+	}
+
+	if modifies(s.configPath) {
+		log.Infof("Discarding cached config of %s at %s", repo.GetFullName(), branch)
+		s.configs.invalidate(configKeyAt(owner, name, branch))
+		if isDefaultBranch {
+			s.configs.invalidate(configKey(owner, name))
+		}
+	}
+
+	for _, key := range s.configs.inheriting(func(inherited model.ConfigReference) bool {
+		return configKey(inherited.Owner, inherited.Repo) == configKey(owner, name) &&
+			(inherited.Ref == branch || (inherited.Ref == "" && isDefaultBranch)) && modifies(inherited.Path)
+	}) {
+		log.Infof("Discarding cached config %s, which inherits a config of %s modified at %s", key, repo.GetFullName(), branch)
+		s.configs.invalidate(key)
+	}
+}
+
+// installationTokens returns the token source of a GitHub App installation, reused across deliveries so that tokens
+// are only created as they expire
+func (s *Server) installationTokens(installationID int64) (oauth2.TokenSource, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if source, ok := s.installations[installationID]; ok {
+		return source, nil
+	}
+
+	source, err := s.app.tokenSource(context.Background(), installationID, "", "")
+	if err != nil {
+		return nil, err
+	}
+	s.installations[installationID] = source
+	return source, nil
+}
+
+// validSignature compares the signature header (sha256=<hex digest>) to the HMAC of the payload
+func (s *Server) validSignature(signature string, payload []byte) bool {
+	digest, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return false
+	}
+	actual, err := hex.DecodeString(digest)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write(payload)
+	return hmac.Equal(actual, mac.Sum(nil))
+}
+
+func newConfigCache() *configCache {
+	return &configCache{configs: make(map[string]cachedConfig)}
+}
+
+// get returns the cached config, or nil if there is none. A nil configCache never has a config.
+func (c *configCache) get(key string) model.Config {
+	if c == nil {
+		return nil
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.configs[key].config
+}
+
+// put caches the config, along with the configs it inherits from. A nil configCache discards the config.
+func (c *configCache) put(key string, config model.Config, inherited ...model.ConfigReference) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.configs[key]; !ok {
+		for len(c.configs) >= maxCachedConfigs && len(c.order) > 0 {
+			delete(c.configs, c.order[0])
+			c.order = c.order[1:]
+		}
+		c.order = append(c.order, key)
+	}
+	c.configs[key] = cachedConfig{config: config, inherited: inherited}
+}
+
+// inheriting returns the keys of the cached configs which inherit a config matching the predicate
+func (c *configCache) inheriting(matches func(inherited model.ConfigReference) bool) []string {
+	if c == nil {
+		return nil
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var keys []string
+	for key, cached := range c.configs {
+		if slices.ContainsFunc(cached.inherited, matches) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// invalidate discards the cached config, if any
+func (c *configCache) invalidate(key string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.configs, key)
+	c.order = slices.DeleteFunc(c.order, func(k string) bool { return k == key })
+}
+
+// configKey identifies a repository's config; GitHub owner and repository names are case-insensitive
+func configKey(owner, repo string) string {
+	return strings.ToLower(owner + "/" + repo)
+}
+
+// configKeyAt identifies a repository's config at a git ref, or the config of the default branch for an empty ref
+func configKeyAt(owner, repo, ref string) string {
+	if ref == "" {
+		return configKey(owner, repo)
+	}
+	return configKey(owner, repo) + "@" + ref
+}
@@ -0,0 +1,285 @@
+package labeler
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-github/v50/github"
+	"github.com/jimschubert/labeler/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const serverConfig = `labels:
+  'bug':
+    include:
+      - '\bbug[s]?\b'
+`
+
+func newTestServer(t *testing.T, mockClient *mockRichClient, opts ...OptFn) *Server {
+	skipTokenCheck = true
+	t.Cleanup(func() {
+		skipTokenCheck = false
+	})
+
+	s, err := NewServer("secret", opts...)
+	assert.NoError(t, err)
+	s.newLabeler = func(opts ...OptFn) (*Labeler, error) {
+		l, err := NewWithOptions(opts...)
+		if err == nil {
+			l.client = mockClient
+		}
+		return l, err
+	}
+	return s
+}
+
+func deliver(s *Server, event string, payload string, secret string) *httptest.ResponseRecorder {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-GitHub-Event", event)
+	req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	return w
+}
+
+func TestNewServer_requires_secret(t *testing.T) {
+	_, err := NewServer("")
+	assert.EqualError(t, err, "a webhook secret is required")
+}
+
+func TestNewServer_app_base_url(t *testing.T) {
+	key := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(newTestKey(t))})
+
+	t.Setenv("GITHUB_API_URL", "https://github.example.com/api/v3/")
+	s, err := NewServer("secret", WithAppID(123), WithPrivateKey(key))
+	assert.NoError(t, err)
+	assert.Equal(t, "https://github.example.com/api/v3/", s.app.baseURL)
+
+	s, err = NewServer("secret", WithAppID(123), WithPrivateKey(key), WithBaseURL("https://ghes.example.com/api/v3/"))
+	assert.NoError(t, err)
+	assert.Equal(t, "https://ghes.example.com/api/v3/", s.app.baseURL)
+}
+
+func TestServer_ServeHTTP_rejects_invalid_signature(t *testing.T) {
+	mockClient := new(mockRichClient)
+	s := newTestServer(t, mockClient)
+
+	w := deliver(s, "ping", `{"zen":"Keep it logically awesome."}`, "wrong")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(`{}`))
+	req.Header.Set("X-GitHub-Event", "ping")
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "missing signature")
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	mockClient.AssertExpectations(t)
+}
+
+func TestServer_ServeHTTP_ping(t *testing.T) {
+	s := newTestServer(t, new(mockRichClient))
+	w := deliver(s, "ping", `{"zen":"Keep it logically awesome."}`, "secret")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestServer_ServeHTTP_labels_and_caches_config(t *testing.T) {
+	mockClient := new(mockRichClient)
+	s := newTestServer(t, mockClient)
+
+	mockClient.On("DownloadContents", mock.Anything, "owner", "repo", ".github/labeler.yml", mock.Anything).
+		Return(io.NopCloser(bytes.NewReader([]byte(serverConfig))), nil, nil).Once()
+	mockClient.On("AddLabelsToIssue", mock.Anything, "owner", "repo", 1, []string{"bug"}).
+		Return([]*github.Label{{Name: ptr("bug")}}, nil, nil).Once()
+	mockClient.On("AddLabelsToIssue", mock.Anything, "owner", "repo", 2, []string{"bug"}).
+		Return([]*github.Label{{Name: ptr("bug")}}, nil, nil).Once()
+
+	issue := `{"action":"opened","issue":{"number":1,"title":"a bug"},"repository":{"name":"repo","full_name":"owner/repo","owner":{"login":"owner"}}}`
+	assert.Equal(t, http.StatusNoContent, deliver(s, "issues", issue, "secret").Code)
+
+	pr := `{"action":"opened","number":2,"pull_request":{"number":2,"title":"fix bugs"},"repository":{"name":"repo","full_name":"owner/repo","owner":{"login":"owner"}}}`
+	assert.Equal(t, http.StatusNoContent, deliver(s, "pull_request", pr, "secret").Code)
+
+	closed := `{"action":"closed","issue":{"number":3,"title":"a bug"},"repository":{"name":"repo","full_name":"owner/repo","owner":{"login":"owner"}}}`
+	assert.Equal(t, http.StatusNoContent, deliver(s, "issues", closed, "secret").Code)
+
+	mockClient.AssertNumberOfCalls(t, "DownloadContents", 1)
+	mockClient.AssertExpectations(t)
+}
+
+func TestServer_ServeHTTP_push_invalidates_config(t *testing.T) {
+	mockClient := new(mockRichClient)
+	s := newTestServer(t, mockClient)
+
+	mockClient.On("DownloadContents", mock.Anything, "owner", "repo", ".github/labeler.yml", mock.Anything).
+		Return(io.NopCloser(bytes.NewReader([]byte(serverConfig))), nil, nil).Once()
+	mockClient.On("DownloadContents", mock.Anything, "owner", "repo", ".github/labeler.yml", mock.Anything).
+		Return(io.NopCloser(bytes.NewReader([]byte(`labels:
+  'question':
+    include:
+      - '\bquestion\b'
+`))), nil, nil).Once()
+	mockClient.On("AddLabelsToIssue", mock.Anything, "owner", "repo", 1, []string{"bug"}).
+		Return([]*github.Label{{Name: ptr("bug")}}, nil, nil).Once()
+	mockClient.On("AddLabelsToIssue", mock.Anything, "owner", "repo", 2, []string{"question"}).
+		Return([]*github.Label{{Name: ptr("question")}}, nil, nil).Once()
+
+	issue := `{"action":"opened","issue":{"number":1,"title":"a bug"},"repository":{"name":"repo","full_name":"owner/repo","owner":{"login":"owner"}}}`
+	assert.Equal(t, http.StatusNoContent, deliver(s, "issues", issue, "secret").Code)
+
+	unrelated := `{"ref":"refs/heads/main","commits":[{"id":"abc","modified":["README.md"]}],"repository":{"name":"repo","full_name":"owner/repo","default_branch":"main","owner":{"login":"owner"}}}`
+	assert.Equal(t, http.StatusNoContent, deliver(s, "push", unrelated, "secret").Code)
+	otherBranch := `{"ref":"refs/heads/feature","commits":[{"id":"abc","modified":[".github/labeler.yml"]}],"repository":{"name":"repo","full_name":"owner/repo","default_branch":"main","owner":{"login":"owner"}}}`
+	assert.Equal(t, http.StatusNoContent, deliver(s, "push", otherBranch, "secret").Code)
+	assert.NotNil(t, s.configs.get("owner/repo"))
+
+	push := `{"ref":"refs/heads/main","commits":[{"id":"def","modified":[".github/labeler.yml"]}],"repository":{"name":"repo","full_name":"owner/repo","default_branch":"main","owner":{"login":"owner"}}}`
+	assert.Equal(t, http.StatusNoContent, deliver(s, "push", push, "secret").Code)
+	assert.Nil(t, s.configs.get("owner/repo"))
+
+	question := `{"action":"edited","issue":{"number":2,"title":"a bug? no, a question"},"repository":{"name":"repo","full_name":"owner/repo","owner":{"login":"owner"}}}`
+	assert.Equal(t, http.StatusNoContent, deliver(s, "issues", question, "secret").Code)
+
+	mockClient.AssertNumberOfCalls(t, "DownloadContents", 2)
+	mockClient.AssertExpectations(t)
+}
+
+func TestServer_ServeHTTP_issue_comment_commands(t *testing.T) {
+	mockClient := new(mockRichClient)
+	s := newTestServer(t, mockClient)
+
+	mockClient.On("DownloadContents", mock.Anything, "owner", "repo", ".github/labeler.yml", mock.Anything).
+		Return(io.NopCloser(bytes.NewReader([]byte(serverConfig))), nil, nil).Once()
+	mockClient.On("GetPermissionLevel", mock.Anything, "owner", "repo", "maintainer").
+		Return(&github.RepositoryPermissionLevel{Permission: ptr("write")}, nil, nil).Once()
+	mockClient.On("AddLabelsToIssue", mock.Anything, "owner", "repo", 4, []string{"bug"}).
+		Return([]*github.Label{{Name: ptr("bug")}}, nil, nil).Once()
+
+	comment := `{"action":"created","issue":{"number":4,"title":"crash"},"comment":{"body":"/label bug","user":{"login":"maintainer","type":"User"}},` +
+		`"repository":{"name":"repo","full_name":"owner/repo","owner":{"login":"owner"}}}`
+	assert.Equal(t, http.StatusNoContent, deliver(s, "issue_comment", comment, "secret").Code)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestServer_ServeHTTP_caches_config_by_base_commit(t *testing.T) {
+	mockClient := new(mockRichClient)
+	s := newTestServer(t, mockClient)
+
+	mockClient.On("DownloadContents", mock.Anything, "owner", "repo", ".github/labeler.yml", &github.RepositoryContentGetOptions{Ref: "abc123"}).
+		Return(io.NopCloser(bytes.NewReader([]byte(serverConfig))), nil, nil).Once()
+	mockClient.On("AddLabelsToIssue", mock.Anything, "owner", "repo", 1, []string{"bug"}).
+		Return([]*github.Label{{Name: ptr("bug")}}, nil, nil).Once()
+	mockClient.On("AddLabelsToIssue", mock.Anything, "owner", "repo", 2, []string{"bug"}).
+		Return([]*github.Label{{Name: ptr("bug")}}, nil, nil).Once()
+
+	for _, number := range []string{"1", "2"} {
+		pr := `{"action":"opened","number":` + number + `,"pull_request":{"number":` + number + `,"title":"fix bugs","base":{"ref":"main","sha":"abc123"}},` +
+			`"repository":{"name":"repo","full_name":"owner/repo","owner":{"login":"owner"}}}`
+		assert.Equal(t, http.StatusNoContent, deliver(s, "pull_request", pr, "secret").Code)
+	}
+
+	push := `{"ref":"refs/heads/main","commits":[{"id":"def","modified":[".github/labeler.yml"]}],"repository":{"name":"repo","full_name":"owner/repo","default_branch":"main","owner":{"login":"owner"}}}`
+	assert.Equal(t, http.StatusNoContent, deliver(s, "push", push, "secret").Code)
+	assert.NotNil(t, s.configs.get(configKeyAt("owner", "repo", "abc123")), "the config of a commit never changes")
+
+	mockClient.AssertNumberOfCalls(t, "DownloadContents", 1)
+	mockClient.AssertExpectations(t)
+}
+
+func TestConfigCache_put_discards_oldest(t *testing.T) {
+	c := newConfigCache()
+	first := &model.SimpleConfig{}
+	c.put("first", first)
+	for i := 1; i < maxCachedConfigs; i++ {
+		c.put(fmt.Sprintf("config-%d", i), &model.SimpleConfig{})
+	}
+	c.put("first", first)
+	assert.Same(t, first, c.get("first"))
+
+	c.put("last", &model.SimpleConfig{})
+	assert.Nil(t, c.get("first"))
+	assert.NotNil(t, c.get("config-1"))
+	assert.NotNil(t, c.get("last"))
+}
+
+func TestServer_ServeHTTP_push_invalidates_config_directory(t *testing.T) {
+	mockClient := new(mockRichClient)
+	s := newTestServer(t, mockClient, WithConfigPath(".github/labeler.d/"))
+
+	mockClient.On("GetContents", mock.Anything, "owner", "repo", ".github/labeler.d", mock.Anything).
+		Return([]*github.RepositoryContent{{Name: ptr("a.yml"), Type: ptr("file")}}, nil)
+	mockClient.On("DownloadContents", mock.Anything, "owner", "repo", ".github/labeler.d/a.yml", mock.Anything).
+		Return(io.NopCloser(bytes.NewReader([]byte(serverConfig))), nil, nil)
+	mockClient.On("AddLabelsToIssue", mock.Anything, "owner", "repo", 1, []string{"bug"}).
+		Return([]*github.Label{{Name: ptr("bug")}}, nil, nil).Once()
+
+	issue := `{"action":"opened","issue":{"number":1,"title":"a bug"},"repository":{"name":"repo","full_name":"owner/repo","owner":{"login":"owner"}}}`
+	assert.Equal(t, http.StatusNoContent, deliver(s, "issues", issue, "secret").Code)
+	assert.NotNil(t, s.configs.get("owner/repo"))
+
+	unrelated := `{"ref":"refs/heads/main","commits":[{"id":"abc","modified":[".github/labeler.yml"]}],"repository":{"name":"repo","full_name":"owner/repo","default_branch":"main","owner":{"login":"owner"}}}`
+	assert.Equal(t, http.StatusNoContent, deliver(s, "push", unrelated, "secret").Code)
+	assert.NotNil(t, s.configs.get("owner/repo"))
+
+	push := `{"ref":"refs/heads/main","commits":[{"id":"def","added":[".github/labeler.d/a.yml"]}],"repository":{"name":"repo","full_name":"owner/repo","default_branch":"main","owner":{"login":"owner"}}}`
+	assert.Equal(t, http.StatusNoContent, deliver(s, "push", push, "secret").Code)
+	assert.Nil(t, s.configs.get("owner/repo"))
+	mockClient.AssertExpectations(t)
+}
+
+func TestServer_ServeHTTP_push_invalidates_inheriting_configs(t *testing.T) {
+	mockClient := new(mockRichClient)
+	s := newTestServer(t, mockClient)
+
+	missing := errors.New("no file named labeler.yml found in .github")
+	mockClient.On("DownloadContents", mock.Anything, "owner", "extending", ".github/labeler.yml", mock.Anything).
+		Return(io.NopCloser(bytes.NewReader([]byte("extends: owner/.github\n"))), nil, nil)
+	mockClient.On("DownloadContents", mock.Anything, "owner", "fallback", ".github/labeler.yml", mock.Anything).
+		Return(io.NopCloser(nil), nil, missing)
+	for range 2 {
+		mockClient.On("DownloadContents", mock.Anything, "owner", ".github", ".github/labeler.yml", mock.Anything).
+			Return(io.NopCloser(bytes.NewReader([]byte(serverConfig))), nil, nil).Once()
+	}
+	mockClient.On("DownloadContents", mock.Anything, "owner", "standalone", ".github/labeler.yml", mock.Anything).
+		Return(io.NopCloser(bytes.NewReader([]byte(serverConfig))), nil, nil)
+	mockClient.On("AddLabelsToIssue", mock.Anything, "owner", mock.Anything, 1, []string{"bug"}).
+		Return([]*github.Label{{Name: ptr("bug")}}, nil, nil)
+
+	for _, repo := range []string{"extending", "fallback", "standalone"} {
+		issue := `{"action":"opened","issue":{"number":1,"title":"a bug"},"repository":{"name":"` + repo + `","full_name":"owner/` + repo + `","owner":{"login":"owner"}}}`
+		assert.Equal(t, http.StatusNoContent, deliver(s, "issues", issue, "secret").Code)
+		assert.NotNil(t, s.configs.get(configKey("owner", repo)))
+	}
+
+	otherBranch := `{"ref":"refs/heads/feature","commits":[{"id":"abc","modified":[".github/labeler.yml"]}],` +
+		`"repository":{"name":".github","full_name":"owner/.github","default_branch":"main","owner":{"login":"owner"}}}`
+	assert.Equal(t, http.StatusNoContent, deliver(s, "push", otherBranch, "secret").Code)
+	assert.NotNil(t, s.configs.get(configKey("owner", "extending")))
+
+	push := `{"ref":"refs/heads/main","commits":[{"id":"def","modified":[".github/labeler.yml"]}],` +
+		`"repository":{"name":".github","full_name":"owner/.github","default_branch":"main","owner":{"login":"owner"}}}`
+	assert.Equal(t, http.StatusNoContent, deliver(s, "push", push, "secret").Code)
+	assert.Nil(t, s.configs.get(configKey("owner", "extending")), "configs extending the pushed config are discarded")
+	assert.Nil(t, s.configs.get(configKey("owner", "fallback")), "configs falling back to the pushed config are discarded")
+	assert.NotNil(t, s.configs.get(configKey("owner", "standalone")))
+	mockClient.AssertExpectations(t)
+}